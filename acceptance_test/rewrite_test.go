@@ -49,6 +49,36 @@ func TestRewrite(t *testing.T) {
 		defer cancel()
 		testRewrite(t, ctx, rewrite.PkgErrors, "testdata/xerrors/main.go", "testdata/pkgerrors/main.go")
 	})
+	t.Run("go-errors to cockroach-errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.CockroachErrors, "testdata/goerrors/main.go", "testdata/cockroacherrors/main.go")
+	})
+	t.Run("xerrors to cockroach-errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.CockroachErrors, "testdata/xerrors/main.go", "testdata/cockroacherrors/main.go")
+	})
+	t.Run("pkg-errors to cockroach-errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.CockroachErrors, "testdata/pkgerrors/main.go", "testdata/cockroacherrors/main.go")
+	})
+	t.Run("cockroach-errors to go-errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.GoErrors, "testdata/cockroacherrors/main.go", "testdata/goerrors/main.go")
+	})
+	t.Run("cockroach-errors to xerrors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.Xerrors, "testdata/cockroacherrors/main.go", "testdata/xerrors/main.go")
+	})
+	t.Run("cockroach-errors to pkg-errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		testRewrite(t, ctx, rewrite.PkgErrors, "testdata/cockroacherrors/main.go", "testdata/pkgerrors/main.go")
+	})
 }
 
 func testRewrite(t *testing.T, ctx context.Context, target rewrite.Method, fixtureFilename, wantFilename string) {
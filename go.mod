@@ -3,10 +3,19 @@ module github.com/int128/errto
 go 1.13
 
 require (
-	github.com/google/go-cmp v0.4.0
+	github.com/cockroachdb/errors v1.11.3
+	github.com/cockroachdb/logtags v0.0.0-20241215232642-bb51bb14a506 // indirect
+	github.com/go-errors/errors v1.5.1
+	github.com/google/go-cmp v0.5.9
+	github.com/hashicorp/go-multierror v1.1.1
+	github.com/juju/errors v1.0.0
 	github.com/pkg/errors v0.9.1
+	github.com/rotisserie/eris v0.5.4
 	github.com/spf13/cobra v0.0.6
 	github.com/spf13/pflag v1.0.5
-	golang.org/x/tools v0.0.0-20200403190813-44a64ad78b9b
-	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
+	go.uber.org/multierr v1.11.0
+	golang.org/x/tools v0.7.0
+	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2
+	gopkg.in/yaml.v2 v2.4.0
+	k8s.io/apimachinery v0.17.4
 )
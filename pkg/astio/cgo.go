@@ -0,0 +1,77 @@
+package astio
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// usesCgo returns true if the package has the files processed by cgo.
+// go/packages parses the files generated by cgo in the build cache instead of the source files.
+func usesCgo(pkg *packages.Package) bool {
+	goFiles := make(map[string]bool)
+	for _, name := range pkg.GoFiles {
+		goFiles[name] = true
+	}
+	for _, name := range pkg.CompiledGoFiles {
+		if !goFiles[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreCgoFiles replaces the syntax trees generated by cgo with the source files,
+// so that the source files are rewritten in their original form.
+// The source files are type-checked with the fake package C,
+// and therefore the types of the expressions of C are not available.
+func restoreCgoFiles(pkg *packages.Package) error {
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(pkg.Fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+	imports := make(map[string]*types.Package)
+	for _, p := range pkg.Types.Imports() {
+		imports[p.Path()] = p
+	}
+	cfg := types.Config{
+		FakeImportC: true,
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if p, ok := imports[path]; ok {
+				return p, nil
+			}
+			return nil, fmt.Errorf("package %s is not loaded", path)
+		}),
+		Error: func(error) {}, // ignore the errors of the references to package C
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	tpkg, _ := cfg.Check(pkg.Types.Path(), pkg.Fset, files, info)
+	pkg.Syntax = files
+	pkg.CompiledGoFiles = pkg.GoFiles
+	pkg.Types = tpkg
+	pkg.TypesInfo = info
+	return nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,26 @@
+package astio
+
+import (
+	"go/ast"
+	"regexp"
+)
+
+// generatedPattern is the standard header of generated files.
+// See https://golang.org/s/generatedcode
+var generatedPattern = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// IsGenerated returns true if the file has the header of generated code
+// before the package clause.
+func IsGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			return false
+		}
+		for _, comment := range group.List {
+			if generatedPattern.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
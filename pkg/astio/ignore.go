@@ -0,0 +1,179 @@
+package astio
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// IgnoreDirective is the comment to leave the function calls as they are.
+//
+//	//errto:ignore                     the function calls on the next line or the same line
+//	//errto:ignore-file                the function calls in the file
+//	//errto:ignore-package             the function calls in the package
+//	//errto:ignore Wrap,WithStack      only the functions of the names
+//	//errto:ignore Wrap // reason      the reason is optional
+//
+// A function name may be qualified, e.g. github.com/pkg/errors.Wrap.
+const IgnoreDirective = "//errto:ignore"
+
+type ignoreScope int
+
+const (
+	ignoreLine = ignoreScope(iota)
+	ignoreFile
+	ignorePackage
+)
+
+type ignoreComment struct {
+	scope      ignoreScope
+	line       int
+	standalone bool            // the comment is on its own line
+	functions  map[string]bool // nil for all functions
+}
+
+// parseIgnoreComment parses the comment text.
+// It returns false if the comment is not a directive.
+func parseIgnoreComment(text string) (ignoreComment, bool) {
+	var c ignoreComment
+	if !strings.HasPrefix(text, IgnoreDirective) {
+		return c, false
+	}
+	rest := strings.TrimPrefix(text, IgnoreDirective)
+	switch {
+	case strings.HasPrefix(rest, "-file"):
+		c.scope, rest = ignoreFile, strings.TrimPrefix(rest, "-file")
+	case strings.HasPrefix(rest, "-package"):
+		c.scope, rest = ignorePackage, strings.TrimPrefix(rest, "-package")
+	}
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return c, false
+	}
+	if i := strings.Index(rest, "//"); i >= 0 {
+		rest = rest[:i] // reason
+	}
+	for _, name := range strings.Split(strings.TrimSpace(rest), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if c.functions == nil {
+			c.functions = make(map[string]bool)
+		}
+		c.functions[name] = true
+	}
+	return c, true
+}
+
+func (c *ignoreComment) matches(path, name string) bool {
+	return c.functions == nil || c.functions[name] || c.functions[path+"."+name]
+}
+
+// IgnoredCalls returns the package function calls in the file which are marked by IgnoreDirective.
+// The directive of the package scope may be in any file of the package.
+func IgnoredCalls(pkg *packages.Package, file *ast.File) map[*ast.CallExpr]bool {
+	comments := ignoreCommentsOf(pkg, file)
+	if len(comments) == 0 {
+		return nil
+	}
+	ignored := make(map[*ast.CallExpr]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		pkgName, ok := pkg.TypesInfo.ObjectOf(x).(*types.PkgName)
+		if !ok {
+			return true
+		}
+		start, end := pkg.Fset.PositionFor(call.Pos(), false).Line, pkg.Fset.PositionFor(call.End(), false).Line
+		for _, c := range comments {
+			if c.matches(pkgName.Imported().Path(), fun.Sel.Name) && c.covers(start, end) {
+				ignored[call] = true
+			}
+		}
+		return true
+	})
+	return ignored
+}
+
+// IgnoredStmt returns true if the statement is marked by IgnoreDirective,
+// where the statement is regarded as the function of the import path.
+// This is for the syntax which is not a function call, such as a walk of the error chain.
+// The directive of the line scope must be on the first line of the statement or the line before it.
+func IgnoredStmt(pkg *packages.Package, file *ast.File, stmt ast.Stmt, path, name string) bool {
+	start := pkg.Fset.PositionFor(stmt.Pos(), false).Line
+	for _, c := range ignoreCommentsOf(pkg, file) {
+		if c.matches(path, name) && c.covers(start, start) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreCommentsOf returns the directives which apply to the file.
+func ignoreCommentsOf(pkg *packages.Package, file *ast.File) []ignoreComment {
+	var comments []ignoreComment
+	for _, f := range pkg.Syntax {
+		for _, c := range findIgnoreComments(pkg, f) {
+			if c.scope == ignorePackage || f == file {
+				comments = append(comments, c)
+			}
+		}
+	}
+	return comments
+}
+
+// covers returns true if the directive applies to the code between the lines.
+func (c *ignoreComment) covers(start, end int) bool {
+	if c.scope != ignoreLine {
+		return true
+	}
+	return (start <= c.line && c.line <= end) || (c.standalone && c.line == start-1)
+}
+
+func findIgnoreComments(pkg *packages.Package, file *ast.File) []ignoreComment {
+	var comments []ignoreComment
+	var codeLines map[int]bool
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			c, ok := parseIgnoreComment(comment.Text)
+			if !ok {
+				continue
+			}
+			c.line = pkg.Fset.PositionFor(comment.Pos(), false).Line
+			if c.scope == ignoreLine {
+				if codeLines == nil {
+					codeLines = findCodeLines(pkg, file)
+				}
+				c.standalone = !codeLines[c.line]
+			}
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// findCodeLines returns the lines which have any node.
+func findCodeLines(pkg *packages.Package, file *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.File, *ast.Comment, *ast.CommentGroup:
+			return node != nil
+		}
+		lines[pkg.Fset.PositionFor(node.Pos(), false).Line] = true
+		lines[pkg.Fset.PositionFor(node.End(), false).Line] = true
+		return true
+	})
+	return lines
+}
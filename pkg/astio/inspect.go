@@ -12,6 +12,12 @@ type Visitor interface {
 	PackageFunctionCall(call PackageFunctionCall) error
 }
 
+// IgnoredCallVisitor is an optional interface of Visitor,
+// which receives the function calls marked by IgnoreDirective instead of PackageFunctionCall.
+type IgnoredCallVisitor interface {
+	IgnoredPackageFunctionCall(call PackageFunctionCall)
+}
+
 type PackageFunctionCall struct {
 	Position      token.Position
 	Call          *ast.CallExpr
@@ -37,7 +43,16 @@ func (call *PackageFunctionCall) SetArgs(args []ast.Expr) {
 	call.Call.Args = args
 }
 
+// Inspect calls the visitor for each package function call in the file.
+// The function calls marked by IgnoreDirective are not passed to PackageFunctionCall.
 func Inspect(pkg *packages.Package, file *ast.File, v Visitor) error {
+	return InspectWithIgnoredCalls(pkg, file, IgnoredCalls(pkg, file), v)
+}
+
+// InspectWithIgnoredCalls calls the visitor in the same way as Inspect,
+// but it uses the function calls returned by IgnoredCalls in advance.
+// The caller can share them with the other passes of the file.
+func InspectWithIgnoredCalls(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, v Visitor) error {
 	var lastErr error
 	ast.Inspect(file, func(node ast.Node) bool {
 		switch node := node.(type) {
@@ -49,14 +64,21 @@ func Inspect(pkg *packages.Package, file *ast.File, v Visitor) error {
 				case *ast.Ident:
 					switch o := pkg.TypesInfo.ObjectOf(x).(type) {
 					case *types.PkgName:
-						if err := v.PackageFunctionCall(PackageFunctionCall{
+						call := PackageFunctionCall{
 							Position:      p,
 							Call:          node,
 							TargetPkg:     x,
 							TargetPkgName: o,
 							TargetFun:     fun,
 							TypesInfo:     pkg.TypesInfo,
-						}); err != nil {
+						}
+						if ignored[node] {
+							if iv, ok := v.(IgnoredCallVisitor); ok {
+								iv.IgnoredPackageFunctionCall(call)
+							}
+							return true
+						}
+						if err := v.PackageFunctionCall(call); err != nil {
 							lastErr = err
 							return false
 						}
@@ -3,22 +3,89 @@ package astio
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// BuildConfig represents the build tags and platform to load the packages.
+type BuildConfig struct {
+	Tags     []string
+	Platform string // GOOS/GOARCH, e.g. linux/amd64. If empty, the host platform is used.
+}
+
+func (c BuildConfig) String() string {
+	s := c.Platform
+	if s == "" {
+		s = "host"
+	}
+	if len(c.Tags) > 0 {
+		s += " (tags " + strings.Join(c.Tags, ",") + ")"
+	}
+	return s
+}
+
+// ParsePlatform returns GOOS and GOARCH of the platform, e.g. linux/amd64.
+func ParsePlatform(platform string) (string, string, error) {
+	elems := strings.Split(platform, "/")
+	if len(elems) != 2 || elems[0] == "" || elems[1] == "" {
+		return "", "", fmt.Errorf("platform must be GOOS/GOARCH but was %s", platform)
+	}
+	return elems[0], elems[1], nil
+}
+
 func Load(ctx context.Context, pkgNames ...string) ([]*packages.Package, error) {
+	return LoadWithConfig(ctx, BuildConfig{}, "", pkgNames...)
+}
+
+// LoadWithConfig loads the packages with the build tags and platform.
+// The package patterns are resolved in the directory, or the current directory if dir is empty.
+func LoadWithConfig(ctx context.Context, c BuildConfig, dir string, pkgNames ...string) ([]*packages.Package, error) {
+	return load(ctx, c, dir, false, pkgNames)
+}
+
+// LoadPartially loads the packages in the same way as LoadWithConfig,
+// but it does not fail if any package has an error, such as a type error.
+// The caller should check Errors of each package.
+func LoadPartially(ctx context.Context, c BuildConfig, dir string, pkgNames ...string) ([]*packages.Package, error) {
+	return load(ctx, c, dir, true, pkgNames)
+}
+
+func load(ctx context.Context, c BuildConfig, dir string, keepGoing bool, pkgNames []string) ([]*packages.Package, error) {
 	cfg := &packages.Config{
 		Context: ctx,
-		Mode:    packages.NeedCompiledGoFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:     dir,
+		Mode:    packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
 		Tests:   true,
 	}
+	if len(c.Tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(c.Tags, ",")}
+	}
+	if c.Platform != "" {
+		goos, goarch, err := ParsePlatform(c.Platform)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch)
+	}
 	pkgs, err := packages.Load(cfg, pkgNames...)
 	if err != nil {
 		return nil, fmt.Errorf("load error: %w", err)
 	}
-	if n := packages.PrintErrors(pkgs); n > 0 {
+	if n := packages.PrintErrors(pkgs); n > 0 && !keepGoing {
 		return nil, fmt.Errorf("load error")
 	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 || !usesCgo(pkg) {
+			continue
+		}
+		if err := restoreCgoFiles(pkg); err != nil {
+			if !keepGoing {
+				return nil, fmt.Errorf("could not load the cgo files of %s: %w", pkg.ID, err)
+			}
+			pkg.Errors = append(pkg.Errors, packages.Error{Msg: fmt.Sprintf("could not load the cgo files: %s", err)})
+		}
+	}
 	return pkgs, nil
 }
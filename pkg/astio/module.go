@@ -0,0 +1,93 @@
+package astio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Module represents a Go module which contains the packages.
+type Module struct {
+	Path      string // module path, e.g. github.com/int128/errto
+	Dir       string // directory containing go.mod
+	GoVersion string // go directive, e.g. 1.13
+}
+
+// FindModule returns the module which contains the directory.
+// It looks for go.mod in the directory and its parents.
+func FindModule(dir string) (*Module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the absolute path of %s: %w", dir, err)
+	}
+	for {
+		name := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(name); err == nil {
+			m, err := readGoMod(name)
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s: %w", name, err)
+			}
+			m.Dir = dir
+			return m, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+func readGoMod(name string) (*Module, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var m Module
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			m.Path = fields[1]
+			if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+				m.Path = unquoted
+			}
+		case "go":
+			m.GoVersion = fields[1]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if m.Path == "" {
+		return nil, errors.New("no module directive")
+	}
+	return &m, nil
+}
+
+// GoVersionAtLeast returns true if the go directive is the version or later.
+// It returns false if the go directive is not given.
+func (m *Module) GoVersionAtLeast(major, minor int) bool {
+	elems := strings.Split(m.GoVersion, ".")
+	if len(elems) < 2 {
+		return false
+	}
+	x, err := strconv.Atoi(elems[0])
+	if err != nil {
+		return false
+	}
+	y, err := strconv.Atoi(strings.TrimFunc(elems[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return false
+	}
+	return x > major || (x == major && y >= minor)
+}
@@ -11,17 +11,27 @@ import (
 
 var wd, _ = os.Getwd()
 
+// Position returns the position of the node in the source file.
+// It ignores //line directives, because the node is rewritten in the source file.
 func Position(pkg *packages.Package, node ast.Node) token.Position {
-	p := pkg.Fset.Position(node.Pos())
-	p.Filename = relative(p.Filename)
+	p := pkg.Fset.PositionFor(node.Pos(), false)
+	p.Filename = Relative(p.Filename)
 	return p
 }
 
+// Filename returns the relative path to the source file of the syntax tree.
 func Filename(pkg *packages.Package, file *ast.File) string {
-	p := pkg.Fset.Position(file.Pos())
-	return relative(p.Filename)
+	return Relative(SourceFilename(pkg, file))
 }
 
-func relative(name string) string {
+// SourceFilename returns the path to the source file of the syntax tree.
+// It ignores //line directives, so that the file is written to the real path.
+func SourceFilename(pkg *packages.Package, file *ast.File) string {
+	return pkg.Fset.PositionFor(file.Pos(), false).Filename
+}
+
+// Relative returns the path relative to the current directory for logging.
+// It returns the path as it is if it is outside the current directory.
+func Relative(name string) string {
 	return strings.TrimPrefix(name, wd+"/")
 }
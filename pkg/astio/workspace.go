@@ -0,0 +1,138 @@
+package astio
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindModuleDirs returns the directories of the modules in the directory.
+// If go.work is found in the directory or its parents, it returns the modules of the use directives
+// which are in the directory.
+// Otherwise, it walks the directory and returns the directories containing go.mod,
+// except vendor, testdata and the directories starting with . or _.
+// The environment variable GOWORK is respected.
+func FindModuleDirs(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the absolute path of %s: %w", dir, err)
+	}
+	name, err := findGoWork(dir)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		uses, err := readGoWork(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", name, err)
+		}
+		var dirs []string
+		for _, use := range uses {
+			if use == dir || strings.HasPrefix(use, dir+string(filepath.Separator)) {
+				dirs = append(dirs, use)
+			}
+		}
+		return dirs, nil
+	}
+	return walkModuleDirs(dir)
+}
+
+// findGoWork returns the path to go.work, or an empty string if not found.
+func findGoWork(dir string) (string, error) {
+	switch env := os.Getenv("GOWORK"); env {
+	case "off":
+		return "", nil
+	case "":
+	default:
+		return filepath.Abs(env)
+	}
+	for {
+		name := filepath.Join(dir, "go.work")
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// readGoWork returns the directories of the use directives in go.work.
+func readGoWork(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var dirs []string
+	var inBlock bool
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+		case fields[0] == "use" && len(fields) >= 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "use" && len(fields) >= 2:
+			fields = fields[1:]
+		default:
+			continue
+		}
+		dir := fields[0]
+		if unquoted, err := strconv.Unquote(dir); err == nil {
+			dir = unquoted
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(name), filepath.FromSlash(dir))
+		}
+		dirs = append(dirs, dir)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func walkModuleDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root {
+			switch name := info.Name(); {
+			case name == "vendor", name == "testdata", strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+				return filepath.SkipDir
+			}
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not walk %s: %w", root, err)
+	}
+	return dirs, nil
+}
@@ -16,6 +16,10 @@ func Run(ctx context.Context, osArgs []string) int {
 		newRewriteToGoErrorsCmd(),
 		newRewriteToXerrorsCmd(),
 		newRewriteToPkgErrorsCmd(),
+		newRewriteToCockroachErrorsCmd(),
+		newRewriteToCustomCmd(),
+		newMigrateCmd(),
+		newDetectCmd(),
 		newDumpCmd(),
 	)
 
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/int128/errto/pkg/rewrite"
+	"github.com/spf13/cobra"
+)
+
+func newDetectCmd() *cobra.Command {
+	var o loadOption
+	c := &cobra.Command{
+		Use:   "detect [flags] PACKAGE...",
+		Short: "Count the function calls of the error libraries and recommend the target",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg, err := o.loadConfig()
+			if err != nil {
+				return err
+			}
+			d, err := rewrite.Detect(c.Context(), o.input(args, cfg))
+			if err != nil {
+				return fmt.Errorf("detect: %w", err)
+			}
+			printDetection(os.Stdout, d)
+			return nil
+		},
+	}
+	o.register(c.Flags())
+	return c
+}
+
+func printDetection(w io.Writer, d *rewrite.Detection) {
+	for _, mu := range d.Modules {
+		if mu.Module != nil {
+			fmt.Fprintf(w, "module %s (go %s)\n", mu.Module.Path, mu.Module.GoVersion)
+		} else {
+			fmt.Fprintf(w, "(no module)\n")
+		}
+		for _, name := range mu.Usage.Libraries() {
+			fmt.Fprintf(w, "  %-40s %6d\n", name, mu.Usage[name])
+		}
+		for _, p := range mu.Packages {
+			var calls []string
+			for _, name := range p.Usage.Libraries() {
+				calls = append(calls, fmt.Sprintf("%s=%d", name, p.Usage[name]))
+			}
+			fmt.Fprintf(w, "  package %s: %s\n", p.Path, strings.Join(calls, " "))
+		}
+		fmt.Fprintf(w, "  recommended target: %s\n", mu.Target)
+	}
+	fmt.Fprintf(w, "recommended target: %s\n", d.Target)
+}
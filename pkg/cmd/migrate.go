@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/int128/errto/pkg/config"
+	"github.com/int128/errto/pkg/rewrite"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	var o rewriteOption
+	var from []string
+	var to string
+	c := &cobra.Command{
+		Use:   "migrate --from SOURCE --to TARGET [flags] PACKAGE...",
+		Short: "Rewrite the packages from the selected sources to the target",
+		Long: `Rewrite the packages from the selected sources to the target.
+The other packages are left as they are.
+
+SOURCE is either a method, a well-known import path or an import path of the package declared in ` + config.Filename + `,
+e.g. pkg-errors or github.com/juju/errors.
+TARGET is either a method or an import path of the package declared in ` + config.Filename + `.
+The method is one of go-errors, xerrors, pkg-errors, cockroach-errors and custom.
+If TARGET is auto, the target is detected in the same way as the detect command.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg, err := o.loadConfig()
+			if err != nil {
+				return err
+			}
+			target, customTarget, err := parseMigrateTarget(cfg, to)
+			if err != nil {
+				return err
+			}
+			var sources []string
+			for _, source := range from {
+				paths, err := rewrite.SourceImportPaths(source, cfg.Packages)
+				if err != nil {
+					return fmt.Errorf("--from: %w", err)
+				}
+				sources = append(sources, paths...)
+			}
+			in := o.input(args, cfg, target)
+			in.Sources = sources
+			in.CustomTarget = customTarget
+			if err := rewrite.Do(c.Context(), in); err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			return nil
+		},
+	}
+	o.register(c.Flags())
+	c.Flags().StringArrayVar(&from, "from", nil, "Method or import path of the package to rewrite from (repeatable)")
+	c.Flags().StringVar(&to, "to", "", "Method or import path of the package to rewrite with")
+	_ = c.MarkFlagRequired("from")
+	_ = c.MarkFlagRequired("to")
+	return c
+}
+
+// parseMigrateTarget returns the method of the target.
+// If the target is custom or an import path, it returns the in-house package as well.
+func parseMigrateTarget(cfg *config.Config, to string) (rewrite.Method, string, error) {
+	m, err := rewrite.ParseMethod(to)
+	if err != nil {
+		if cfg.FindPackage(to) == nil {
+			return 0, "", fmt.Errorf("--to must be a method or an import path declared in %s: %w", config.Filename, err)
+		}
+		return rewrite.Custom, to, nil
+	}
+	if m == rewrite.Custom {
+		if len(cfg.Packages) == 0 {
+			return 0, "", fmt.Errorf("no package is declared in %s", config.Filename)
+		}
+		return rewrite.Custom, cfg.Packages[0].Path, nil
+	}
+	return m, "", nil
+}
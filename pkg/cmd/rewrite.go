@@ -3,44 +3,39 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/int128/errto/pkg/config"
 	"github.com/int128/errto/pkg/rewrite"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 func newRewriteToGoErrorsCmd() *cobra.Command {
-	var o rewriteOption
-	c := &cobra.Command{
-		Use:   "go-errors [flags] PACKAGE...",
-		Short: "Rewrite the packages with Go errors (fmt, errors)",
-		RunE: func(c *cobra.Command, args []string) error {
-			in := rewrite.Input{
-				PkgNames: args,
-				Target:   rewrite.GoErrors,
-				DryRun:   o.dryRun,
-			}
-			if err := rewrite.Do(c.Context(), in); err != nil {
-				return fmt.Errorf("rewrite: %w", err)
-			}
-			return nil
-		},
-	}
-	o.register(c.Flags())
-	return c
+	return newRewriteCmd(rewrite.GoErrors, "Rewrite the packages with Go errors (fmt, errors)")
 }
 
 func newRewriteToXerrorsCmd() *cobra.Command {
+	return newRewriteCmd(rewrite.Xerrors, "Rewrite the packages with golang.org/x/xerrors")
+}
+
+func newRewriteToPkgErrorsCmd() *cobra.Command {
+	return newRewriteCmd(rewrite.PkgErrors, "Rewrite the packages with github.com/pkg/errors")
+}
+
+func newRewriteToCockroachErrorsCmd() *cobra.Command {
+	return newRewriteCmd(rewrite.CockroachErrors, "Rewrite the packages with github.com/cockroachdb/errors")
+}
+
+func newRewriteCmd(target rewrite.Method, short string) *cobra.Command {
 	var o rewriteOption
 	c := &cobra.Command{
-		Use:   "xerrors [flags] PACKAGE...",
-		Short: "Rewrite the packages with golang.org/x/xerrors",
+		Use:   target.String() + " [flags] PACKAGE...",
+		Short: short,
 		RunE: func(c *cobra.Command, args []string) error {
-			in := rewrite.Input{
-				PkgNames: args,
-				Target:   rewrite.Xerrors,
-				DryRun:   o.dryRun,
+			cfg, err := o.loadConfig()
+			if err != nil {
+				return err
 			}
-			if err := rewrite.Do(c.Context(), in); err != nil {
+			if err := rewrite.Do(c.Context(), o.input(args, cfg, target)); err != nil {
 				return fmt.Errorf("rewrite: %w", err)
 			}
 			return nil
@@ -50,17 +45,25 @@ func newRewriteToXerrorsCmd() *cobra.Command {
 	return c
 }
 
-func newRewriteToPkgErrorsCmd() *cobra.Command {
+func newRewriteToCustomCmd() *cobra.Command {
 	var o rewriteOption
+	var targetPackage string
 	c := &cobra.Command{
-		Use:   "pkg-errors [flags] PACKAGE...",
-		Short: "Rewrite the packages with github.com/pkg/errors",
+		Use:   "custom [flags] PACKAGE...",
+		Short: "Rewrite the packages with an in-house package declared in " + config.Filename,
 		RunE: func(c *cobra.Command, args []string) error {
-			in := rewrite.Input{
-				PkgNames: args,
-				Target:   rewrite.PkgErrors,
-				DryRun:   o.dryRun,
+			cfg, err := o.loadConfig()
+			if err != nil {
+				return err
+			}
+			if len(cfg.Packages) == 0 {
+				return fmt.Errorf("no package is declared in %s", config.Filename)
 			}
+			if targetPackage == "" {
+				targetPackage = cfg.Packages[0].Path
+			}
+			in := o.input(args, cfg, rewrite.Custom)
+			in.CustomTarget = targetPackage
 			if err := rewrite.Do(c.Context(), in); err != nil {
 				return fmt.Errorf("rewrite: %w", err)
 			}
@@ -68,13 +71,96 @@ func newRewriteToPkgErrorsCmd() *cobra.Command {
 		},
 	}
 	o.register(c.Flags())
+	c.Flags().StringVar(&targetPackage, "package", "", "Import path of the package to rewrite with (default: the first package in the config file)")
 	return c
 }
 
+// rewriteOption represents the options of the rewrite and migrate commands.
 type rewriteOption struct {
-	dryRun bool
+	loadOption
+	dryRun          bool
+	strict          bool
+	sentinelPackage string
+	inlineHelpers   bool
+	onlyFunctions   []string
+	skipFunctions   []string
 }
 
 func (o *rewriteOption) register(f *pflag.FlagSet) {
+	o.loadOption.register(f)
 	f.BoolVar(&o.dryRun, "dry-run", false, "Do not write files actually")
+	f.BoolVar(&o.strict, "strict", false, "Exit with an error if any function call could not be rewritten")
+	f.StringVar(&o.sentinelPackage, "sentinel-package", "", "Import path of the package of sentinel errors for github.com/juju/errors (default: errkind in the module root)")
+	f.BoolVar(&o.inlineHelpers, "inline-helpers", false, "Inline the unexported functions which only return an error, e.g. func wrap(err error) error { return errors.WithStack(err) }")
+	f.StringArrayVar(&o.onlyFunctions, "only", nil, "Qualified name of the function to rewrite, e.g. github.com/pkg/errors.Wrap (repeatable)")
+	f.StringArrayVar(&o.skipFunctions, "skip", nil, "Qualified name of the function to leave as it is, e.g. github.com/pkg/errors.WithStack (repeatable)")
+}
+
+// input returns the input to rewrite the packages with the target.
+func (o *rewriteOption) input(pkgNames []string, cfg *config.Config, target rewrite.Method) rewrite.Input {
+	in := o.loadOption.input(pkgNames, cfg)
+	in.Target = target
+	in.DryRun = o.dryRun
+	in.Strict = o.strict
+	in.InlineHelpers = o.inlineHelpers
+	in.SentinelPackage = o.sentinelPackage
+	in.OnlyFunctions = o.onlyFunctions
+	in.SkipFunctions = o.skipFunctions
+	return in
+}
+
+// loadOption represents the options to load the packages,
+// which are shared by the rewrite, migrate and detect commands.
+type loadOption struct {
+	configFile       string
+	keepGoing        bool
+	excludePatterns  []string
+	includeGenerated bool
+	tags             []string
+	platforms        []string
+	allModules       bool
+}
+
+func (o *loadOption) register(f *pflag.FlagSet) {
+	f.BoolVar(&o.keepGoing, "keep-going", false, "Skip the packages which have any error, such as a type error, instead of exiting")
+	f.StringArrayVar(&o.excludePatterns, "exclude", nil, "Glob pattern of the files to leave as they are, e.g. *_test.go or internal/gen (repeatable)")
+	f.BoolVar(&o.includeGenerated, "include-generated", false, "Include the generated files as well")
+	f.StringSliceVar(&o.tags, "tags", nil, "Comma-separated build tags to load the packages, e.g. integration,e2e")
+	f.StringSliceVar(&o.platforms, "platforms", nil, "Comma-separated platforms to load the packages, e.g. linux/amd64,windows/amd64,darwin/arm64 (default: the host)")
+	f.BoolVar(&o.allModules, "all-modules", false, "Load the packages in each module of go.work, or each directory containing go.mod")
+	f.StringVar(&o.configFile, "config", "", "Path to the config file (default: "+config.Filename+" in the current directory or its parents)")
+}
+
+// input returns the input to load the packages.
+func (o *loadOption) input(pkgNames []string, cfg *config.Config) rewrite.Input {
+	return rewrite.Input{
+		PkgNames:         pkgNames,
+		KeepGoing:        o.keepGoing,
+		CustomPackages:   cfg.Packages,
+		ExcludePatterns:  o.excludePatterns,
+		IncludeGenerated: o.includeGenerated,
+		Tags:             o.tags,
+		Platforms:        o.platforms,
+		AllModules:       o.allModules,
+	}
+}
+
+// loadConfig loads the config file.
+// It returns an empty config if the file is not found.
+func (o *loadOption) loadConfig() (*config.Config, error) {
+	if o.configFile != "" {
+		cfg, err := config.Load(o.configFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the config: %w", err)
+		}
+		return cfg, nil
+	}
+	cfg, err := config.Find(".")
+	if err != nil {
+		return nil, fmt.Errorf("could not load the config: %w", err)
+	}
+	if cfg == nil {
+		return &config.Config{}, nil
+	}
+	return cfg, nil
 }
@@ -0,0 +1,154 @@
+// Package config provides the configuration file of errto.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Filename is the name of the configuration file.
+const Filename = ".errto.yaml"
+
+// Config represents the configuration file.
+//
+//	packages:
+//	  - path: example.com/internal/errs
+//	    functions:
+//	      New: new
+//	      Wrap: wrap
+//	      Is: is
+type Config struct {
+	Packages []Package `yaml:"packages"` // in-house packages of error helpers
+}
+
+// Package represents an in-house package of error helpers.
+// Each function of the package is mapped to an operation.
+type Package struct {
+	Path      string               `yaml:"path"`      // import path, e.g. example.com/internal/errs
+	Functions map[string]Operation `yaml:"functions"` // function name -> operation
+}
+
+// Operation represents an operation of errors which the function provides.
+// The function must have the same arguments as the function of github.com/pkg/errors.
+type Operation string
+
+const (
+	New         = Operation("new")          // New("MESSAGE")
+	Errorf      = Operation("errorf")       // Errorf("FORMAT", ...)
+	Wrap        = Operation("wrap")         // Wrap(err, "MESSAGE")
+	Wrapf       = Operation("wrapf")        // Wrapf(err, "FORMAT", ...)
+	WithMessage = Operation("with-message") // WithMessage(err, "MESSAGE")
+	WithStack   = Operation("with-stack")   // WithStack(err)
+	Unwrap      = Operation("unwrap")       // Unwrap(err)
+	Is          = Operation("is")           // Is(err, target)
+	As          = Operation("as")           // As(err, target)
+	Cause       = Operation("cause")        // Cause(err)
+)
+
+// Operations are all the operations.
+var Operations = []Operation{New, Errorf, Wrap, Wrapf, WithMessage, WithStack, Unwrap, Is, As, Cause}
+
+func (op Operation) valid() bool {
+	for _, o := range Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Function returns the name of the function of the operation.
+// If multiple functions are mapped to the operation, it returns the first one in alphabetical order.
+// It returns an empty string if no function is mapped.
+func (p *Package) Function(op Operation) string {
+	var names []string
+	for name, o := range p.Functions {
+		if o == op {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// Find looks for the configuration file in the directory and its parents.
+// It returns nil if the file is not found.
+func Find(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the absolute path of %s: %w", dir, err)
+	}
+	for {
+		name := filepath.Join(dir, Filename)
+		if _, err := os.Stat(name); err == nil {
+			return Load(name)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads the configuration file.
+func Load(name string) (*Config, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", name, err)
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", name, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", name, err)
+	}
+	return &c, nil
+}
+
+func (c *Config) validate() error {
+	seen := make(map[string]bool)
+	for _, p := range c.Packages {
+		if p.Path == "" {
+			return errors.New("path of a package must be set")
+		}
+		if seen[p.Path] {
+			return fmt.Errorf("package %s is declared more than once", p.Path)
+		}
+		seen[p.Path] = true
+		if len(p.Functions) == 0 {
+			return fmt.Errorf("package %s must have at least one function", p.Path)
+		}
+		for name, op := range p.Functions {
+			if !token.IsIdentifier(name) {
+				return fmt.Errorf("%s of package %s is not a valid function name", name, p.Path)
+			}
+			if !op.valid() {
+				return fmt.Errorf("%s.%s has unknown operation %q (must be one of %v)", p.Path, name, op, Operations)
+			}
+		}
+	}
+	return nil
+}
+
+// FindPackage returns the package of the import path.
+// It returns nil if the package is not declared.
+func (c *Config) FindPackage(path string) *Package {
+	for i := range c.Packages {
+		if c.Packages[i].Path == path {
+			return &c.Packages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package rewrite
+
+import (
+	"strings"
+
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// brokenPackages skips the packages which have any error, such as a type error,
+// because they cannot be rewritten without the type information.
+// It records the first error of each package for the summary.
+type brokenPackages struct {
+	ids    []string
+	errors map[string]packages.Error // key is the package ID
+}
+
+// filter returns the packages which have no error.
+func (b *brokenPackages) filter(pkgs []*packages.Package) []*packages.Package {
+	var healthy []*packages.Package
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			healthy = append(healthy, pkg)
+			continue
+		}
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue // generated main package of tests
+		}
+		if b.errors == nil {
+			b.errors = make(map[string]packages.Error)
+		}
+		if _, ok := b.errors[pkg.ID]; ok {
+			continue
+		}
+		log.Printf("--- skipped the package %s because of the error", pkg.ID)
+		b.ids = append(b.ids, pkg.ID)
+		b.errors[pkg.ID] = pkg.Errors[0]
+	}
+	return healthy
+}
+
+// printSummary shows the skipped packages and their first errors.
+func (b *brokenPackages) printSummary() {
+	if len(b.ids) == 0 {
+		return
+	}
+	log.Printf("--- skipped %d package(s) because of the errors", len(b.ids))
+	for _, id := range b.ids {
+		log.Printf("%s: %s", id, b.errors[id])
+	}
+}
@@ -0,0 +1,29 @@
+package rewrite
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestBrokenPackages_filter(t *testing.T) {
+	typeError := packages.Error{Pos: "a.go:3:2", Msg: "undefined: x", Kind: packages.TypeError}
+	p := &packages.Package{ID: "p"}
+	pt := &packages.Package{ID: "p [p.test]", Errors: []packages.Error{{Msg: "missing", Kind: packages.ListError}}}
+	q := &packages.Package{ID: "q", Errors: []packages.Error{typeError, {Msg: "second"}}}
+	main := &packages.Package{ID: "p.test", Errors: []packages.Error{{Msg: "main"}}}
+
+	var b brokenPackages
+	healthy := b.filter([]*packages.Package{p, pt, q, main})
+	if len(healthy) != 1 || healthy[0] != p {
+		t.Errorf("filter wants [p] but was %v", healthy)
+	}
+	// the same package of another build configuration
+	b.filter([]*packages.Package{{ID: "q", Errors: []packages.Error{{Msg: "another"}}}})
+	if want := []string{"p [p.test]", "q"}; len(b.ids) != len(want) || b.ids[0] != want[0] || b.ids[1] != want[1] {
+		t.Errorf("ids wants %v but was %v", want, b.ids)
+	}
+	if got := b.errors["q"]; got.Msg != typeError.Msg {
+		t.Errorf("errors[q] wants the first error %v but was %v", typeError, got)
+	}
+}
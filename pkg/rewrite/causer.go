@@ -0,0 +1,81 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// addDelegateMethods adds the method to the types which have only the other method.
+// For example, it adds Unwrap() to the types which implement Cause() of github.com/pkg/errors,
+// so that errors.Is() and errors.As() can see through them.
+//
+//	func (e *T) Cause() error { ... }
+//	func (e *T) Unwrap() error { return e.Cause() }
+//
+// Both methods must have the signature of func() error.
+// If the type already has a field or another method of the name, it is left as it is and reported.
+// It returns the number of added methods.
+func addDelegateMethods(pkg *packages.Package, file *ast.File, from, to string, r *reporter) int {
+	var n int
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Name.Name != from {
+			continue
+		}
+		obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+		if !ok || !isErrorGetter(obj.Type().(*types.Signature)) {
+			continue
+		}
+		recvType := receiverTypeName(fn)
+		if recvType == "" {
+			continue
+		}
+		typeObj := pkg.Types.Scope().Lookup(recvType)
+		if typeObj == nil {
+			continue
+		}
+		if existing, _, _ := types.LookupFieldOrMethod(types.NewPointer(typeObj.Type()), true, pkg.Types, to); existing != nil {
+			if existingFn, ok := existing.(*types.Func); ok && isErrorGetter(existingFn.Type().(*types.Signature)) {
+				continue
+			}
+			r.add(Diagnostic{
+				Position: astio.Position(pkg, fn),
+				Function: fmt.Sprintf("%s.%s.%s", pkg.Types.Path(), recvType, from),
+				Reason:   fmt.Sprintf("%s() cannot be added because %s %s already exists", to, objectKind(existing), to),
+			})
+			continue
+		}
+
+		recvName := "e"
+		if names := fn.Recv.List[0].Names; len(names) == 1 && names[0].Name != "_" {
+			recvName = names[0].Name
+		}
+		recvTypeExpr := exprString(pkg.Fset, fn.Recv.List[0].Type)
+		newFn := newFuncDecl(pkg.Fset, fmt.Sprintf("func (%s %s) %s() error { return %s.%s() }",
+			recvName, recvTypeExpr, to, recvName, from), fn.End())
+		insertDeclAfter(file, fn, newFn)
+		log.Printf("%s: + method %s.%s()", astio.Position(pkg, fn), recvType, to)
+		n++
+	}
+	return n
+}
+
+// isErrorGetter returns true if the signature is func() error.
+func isErrorGetter(sig *types.Signature) bool {
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	return types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+func objectKind(obj types.Object) string {
+	if _, ok := obj.(*types.Var); ok {
+		return "field"
+	}
+	return "method"
+}
@@ -0,0 +1,388 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// unwrapAssertion represents a type assertion to a causer or wrapper interface, such as
+//
+//	c, ok := err.(interface{ Cause() error })
+type unwrapAssertion struct {
+	next   *ast.Ident // c
+	ok     *ast.Ident // ok
+	err    *ast.Ident // err
+	method string     // Cause or Unwrap
+}
+
+// rewriteChainWalks rewrites the hand-rolled walks of the error chain
+// using the type assertions to a causer or wrapper interface.
+// pkgName is the package name of the errors package of the target.
+//
+// For the go-errors and xerrors targets, it rewrites the following syntax:
+//
+//	if c, ok := err.(interface{ Cause() error }); ok {  ->  if c := errors.Unwrap(err); c != nil {
+//		err = c.Cause()                                 ->  	err = c
+//	}                                                   ->  }
+//
+//	for {                                               ->  for errors.Unwrap(err) != nil {
+//		c, ok := err.(interface{ Cause() error })       ->  	err = errors.Unwrap(err)
+//		if !ok {                                        ->  }
+//			break
+//		}
+//		err = c.Cause()
+//	}
+//
+// For the pkg-errors target, a walk of Cause() is rewritten to err = errors.Cause(err),
+// and a walk of Unwrap() is left as it is, because errors.Cause() does not follow Unwrap().
+// For the cockroach-errors target, it is rewritten to err = errors.UnwrapAll(err).
+//
+// If the loop compares the error with a value before unwrapping it,
+// it is collapsed into errors.Is() for all the targets.
+// For the pkg-errors and cockroach-errors targets, a walk of Cause() with a comparison is left as it is,
+// because errors.Is() of them does not follow Cause() in the same way as the loop.
+//
+//	for {                                               ->  return errors.Is(err, ErrNotFound)
+//		if err == ErrNotFound {
+//			return true
+//		}
+//		c, ok := err.(interface{ Unwrap() error })
+//		if !ok {
+//			return false
+//		}
+//		err = c.Unwrap()
+//	}
+//
+// A walk of Cause() is regarded as github.com/pkg/errors.Cause and a walk of Unwrap() as errors.Unwrap,
+// so that it is left as it is if it is marked by the ignore directive or not selected by the filter.
+//
+// This must be called after the function calls are rewritten,
+// because the new function calls do not have the type information.
+// It returns the number of rewritten statements, which need the import of the errors package.
+func rewriteChainWalks(pkg *packages.Package, file *ast.File, filter *callFilter, target Method, pkgName string) int {
+	var n int
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			var c int
+			node.List, c = rewriteChainWalksInStmts(pkg, file, filter, node.List, target, pkgName)
+			n += c
+		case *ast.CaseClause:
+			var c int
+			node.Body, c = rewriteChainWalksInStmts(pkg, file, filter, node.Body, target, pkgName)
+			n += c
+		case *ast.CommClause:
+			var c int
+			node.Body, c = rewriteChainWalksInStmts(pkg, file, filter, node.Body, target, pkgName)
+			n += c
+		}
+		return true
+	})
+	return n
+}
+
+// selectedChainWalk returns true if the walk of the method should be rewritten.
+func selectedChainWalk(pkg *packages.Package, file *ast.File, filter *callFilter, stmt ast.Stmt, method string) bool {
+	path := "errors"
+	if method == "Cause" {
+		path = pkgErrorsImportPath
+	}
+	return filter.selectedFunction(path, method) && !astio.IgnoredStmt(pkg, file, stmt, path, method)
+}
+
+func rewriteChainWalksInStmts(pkg *packages.Package, file *ast.File, filter *callFilter, stmts []ast.Stmt, target Method, pkgName string) ([]ast.Stmt, int) {
+	var n int
+	var newStmts []ast.Stmt
+	for i := 0; i < len(stmts); i++ {
+		stmt := stmts[i]
+		switch stmt := stmt.(type) {
+		case *ast.IfStmt:
+			if a, ok := parseUnwrapIf(pkg, stmt); ok && target != PkgErrors && target != CockroachErrors && selectedChainWalk(pkg, file, filter, stmt, a.method) {
+				log.Printf("%s: %s.(interface{ %s() error }) -> %s.Unwrap()", astio.Position(pkg, stmt), a.err.Name, a.method, pkgName)
+				removeComments(file, stmt.Pos(), stmt.End())
+				mergeLines(pkg.Fset, stmt.Pos(), stmt.End())
+				newStmts = append(newStmts, newStmt(fmt.Sprintf(
+					"if %[1]s := %[3]s.Unwrap(%[2]s); %[1]s != nil {\n%[2]s = %[1]s\n}",
+					a.next.Name, a.err.Name, pkgName), stmt.Pos()))
+				n++
+				continue
+			}
+
+		case *ast.ForStmt:
+			walk, ok := parseChainWalk(pkg, stmt)
+			if !ok || !selectedChainWalk(pkg, file, filter, stmt, walk.method) {
+				break
+			}
+			p := astio.Position(pkg, stmt)
+			if walk.target != nil {
+				if walk.method == "Cause" && (target == PkgErrors || target == CockroachErrors) {
+					break
+				}
+				// the following return statement is no longer reachable
+				if i+1 < len(stmts) && isIdentReturn(stmts[i+1], walk.notFound) {
+					i++
+				} else if stmt.Cond != nil {
+					break
+				}
+				log.Printf("%s: walk of %s() -> %s.Is()", p, walk.method, pkgName)
+				removeComments(file, stmt.Pos(), stmts[i].End())
+				mergeLines(pkg.Fset, stmt.Pos(), stmts[i].End())
+				newStmts = append(newStmts, newStmt(fmt.Sprintf(
+					"return %s.Is(%s, %s)",
+					pkgName, walk.err.Name, exprString(pkg.Fset, walk.target)), stmt.Pos()))
+				n++
+				continue
+			}
+			if target == PkgErrors && walk.method != "Cause" {
+				break
+			}
+			removeComments(file, stmt.Pos(), stmt.End())
+			mergeLines(pkg.Fset, stmt.Pos(), stmt.End())
+			switch target {
+			case PkgErrors, CockroachErrors:
+				funName := "Cause"
+				if target == CockroachErrors {
+					funName = "UnwrapAll"
+				}
+				log.Printf("%s: walk of %s() -> %s.%s()", p, walk.method, pkgName, funName)
+				newStmts = append(newStmts, newStmt(fmt.Sprintf(
+					"%[2]s = %[1]s.%[3]s(%[2]s)",
+					pkgName, walk.err.Name, funName), stmt.Pos()))
+				n++
+				continue
+			}
+			log.Printf("%s: walk of %s() -> %s.Unwrap()", p, walk.method, pkgName)
+			newStmts = append(newStmts, newStmt(fmt.Sprintf(
+				"for %[1]s.Unwrap(%[2]s) != nil {\n%[2]s = %[1]s.Unwrap(%[2]s)\n}",
+				pkgName, walk.err.Name), stmt.Pos()))
+			n++
+			continue
+		}
+		newStmts = append(newStmts, stmt)
+	}
+	return newStmts, n
+}
+
+// parseUnwrapIf parses the following syntax:
+//
+//	if c, ok := err.(interface{ Cause() error }); ok {
+//		err = c.Cause()
+//	}
+func parseUnwrapIf(pkg *packages.Package, stmt *ast.IfStmt) (*unwrapAssertion, bool) {
+	if stmt.Init == nil || stmt.Else != nil || len(stmt.Body.List) != 1 {
+		return nil, false
+	}
+	a, ok := parseUnwrapAssertion(pkg, stmt.Init)
+	if !ok {
+		return nil, false
+	}
+	if cond, ok := stmt.Cond.(*ast.Ident); !ok || !sameObject(pkg, cond, a.ok) {
+		return nil, false
+	}
+	if !isUnwrapAssign(pkg, stmt.Body.List[0], a) {
+		return nil, false
+	}
+	return a, true
+}
+
+// chainWalk represents a loop which walks the error chain.
+type chainWalk struct {
+	unwrapAssertion
+	target   ast.Expr   // the value compared with the error, may be nil
+	notFound *ast.Ident // the value returned if the error is not found, only if target is set
+}
+
+// parseChainWalk parses the following syntax:
+//
+//	for [err != nil] {
+//		[if err == target {
+//			return true
+//		}]
+//		c, ok := err.(interface{ Cause() error })
+//		if !ok {
+//			break or return false
+//		}
+//		err = c.Cause()
+//	}
+func parseChainWalk(pkg *packages.Package, stmt *ast.ForStmt) (*chainWalk, bool) {
+	if stmt.Init != nil || stmt.Post != nil {
+		return nil, false
+	}
+	var walk chainWalk
+	body := stmt.Body.List
+	var compared *ast.Ident
+	if len(body) == 4 {
+		x, target, ok := parseComparison(pkg, body[0])
+		if !ok {
+			return nil, false
+		}
+		compared, walk.target = x, target
+		body = body[1:]
+	}
+	if len(body) != 3 {
+		return nil, false
+	}
+	a, ok := parseUnwrapAssertion(pkg, body[0])
+	if !ok {
+		return nil, false
+	}
+	walk.unwrapAssertion = *a
+	if stmt.Cond != nil && !isNotNil(pkg, stmt.Cond, a.err) {
+		return nil, false
+	}
+	if compared != nil && !sameObject(pkg, compared, a.err) {
+		return nil, false
+	}
+
+	// if !ok { break } or if !ok { return false }
+	ifNotOK, ok := body[1].(*ast.IfStmt)
+	if !ok || ifNotOK.Init != nil || ifNotOK.Else != nil || len(ifNotOK.Body.List) != 1 {
+		return nil, false
+	}
+	not, ok := ifNotOK.Cond.(*ast.UnaryExpr)
+	if !ok || not.Op != token.NOT {
+		return nil, false
+	}
+	if x, ok := not.X.(*ast.Ident); !ok || !sameObject(pkg, x, a.ok) {
+		return nil, false
+	}
+	if walk.target != nil {
+		walk.notFound = ast.NewIdent("false")
+		if !isIdentReturn(ifNotOK.Body.List[0], walk.notFound) {
+			return nil, false
+		}
+	} else {
+		if branch, ok := ifNotOK.Body.List[0].(*ast.BranchStmt); !ok || branch.Tok != token.BREAK || branch.Label != nil {
+			return nil, false
+		}
+	}
+
+	if !isUnwrapAssign(pkg, body[2], a) {
+		return nil, false
+	}
+	return &walk, true
+}
+
+// parseUnwrapAssertion parses c, ok := err.(interface{ Cause() error }).
+// err must be of the error type, so that it can be passed to the functions of the errors package.
+func parseUnwrapAssertion(pkg *packages.Package, stmt ast.Stmt) (*unwrapAssertion, bool) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return nil, false
+	}
+	next, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || next.Name == "_" {
+		return nil, false
+	}
+	okIdent, ok := assign.Lhs[1].(*ast.Ident)
+	if !ok || okIdent.Name == "_" {
+		return nil, false
+	}
+	assert, ok := assign.Rhs[0].(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil {
+		return nil, false
+	}
+	err, ok := assert.X.(*ast.Ident)
+	if !ok || !types.Identical(pkg.TypesInfo.TypeOf(err), types.Universe.Lookup("error").Type()) {
+		return nil, false
+	}
+	iface, ok := pkg.TypesInfo.TypeOf(assert.Type).Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() != 1 {
+		return nil, false
+	}
+	m := iface.Method(0)
+	if m.Name() != "Cause" && m.Name() != "Unwrap" {
+		return nil, false
+	}
+	if !isErrorGetter(m.Type().(*types.Signature)) {
+		return nil, false
+	}
+	return &unwrapAssertion{next: next, ok: okIdent, err: err, method: m.Name()}, true
+}
+
+// isUnwrapAssign returns true if stmt is err = c.Cause().
+func isUnwrapAssign(pkg *packages.Package, stmt ast.Stmt, a *unwrapAssertion) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return false
+	}
+	lhs, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || !sameObject(pkg, lhs, a.err) {
+		return false
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return false
+	}
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || fun.Sel.Name != a.method {
+		return false
+	}
+	x, ok := fun.X.(*ast.Ident)
+	return ok && sameObject(pkg, x, a.next)
+}
+
+// parseComparison parses if err == target { return true }.
+// It returns the compared identifier and the target.
+func parseComparison(pkg *packages.Package, stmt ast.Stmt) (*ast.Ident, ast.Expr, bool) {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil || ifStmt.Else != nil || len(ifStmt.Body.List) != 1 {
+		return nil, nil, false
+	}
+	if !isIdentReturn(ifStmt.Body.List[0], ast.NewIdent("true")) {
+		return nil, nil, false
+	}
+	cond, ok := ifStmt.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.EQL {
+		return nil, nil, false
+	}
+	x, ok := cond.X.(*ast.Ident)
+	if !ok {
+		return nil, nil, false
+	}
+	// the comparison with nil is not the same as errors.Is()
+	if isNil(pkg, cond.Y) {
+		return nil, nil, false
+	}
+	return x, cond.Y, true
+}
+
+// isNotNil returns true if expr is x != nil.
+func isNotNil(pkg *packages.Package, expr ast.Expr, x *ast.Ident) bool {
+	b, ok := expr.(*ast.BinaryExpr)
+	if !ok || b.Op != token.NEQ {
+		return false
+	}
+	bx, ok := b.X.(*ast.Ident)
+	return ok && sameObject(pkg, bx, x) && isNil(pkg, b.Y)
+}
+
+func isNil(pkg *packages.Package, expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = pkg.TypesInfo.ObjectOf(ident).(*types.Nil)
+	return ok
+}
+
+// isIdentReturn returns true if stmt returns the identifier, such as return true.
+func isIdentReturn(stmt ast.Stmt, ident *ast.Ident) bool {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return false
+	}
+	result, ok := ret.Results[0].(*ast.Ident)
+	return ok && result.Name == ident.Name
+}
+
+func sameObject(pkg *packages.Package, a, b *ast.Ident) bool {
+	o := pkg.TypesInfo.ObjectOf(a)
+	return o != nil && o == pkg.TypesInfo.ObjectOf(b)
+}
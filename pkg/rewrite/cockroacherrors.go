@@ -0,0 +1,172 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+type toCockroachErrors struct {
+	sentinels *sentinelPackage
+	customs   customPackages
+	filter    *callFilter
+}
+
+func (t *toCockroachErrors) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
+	var v toCockroachErrorsVisitor
+	v.sentinels = t.sentinels
+	v.errorfWraps = true
+	v.customs = t.customs
+	v.filter = t.filter
+	// compute the ignored calls once, before the passes replace any node of the file
+	ignored := astio.IgnoredCalls(pkg, file)
+	var m int
+	if t.filter.selected(xerrorsImportPath) {
+		m += migrateXerrorsFormatters(pkg, file, &v.reporter)
+		m += migrateXerrorsFrames(pkg, file, &v.reporter)
+	}
+	if t.filter.selected(jujuErrorsImportPath) {
+		m += v.replaceJujuConstErrors(pkg, file, ignored, t.filter, &v.reporter)
+	}
+	if t.filter.selected(erisImportPath) {
+		m += replaceErisToString(pkg, file, ignored, t.filter)
+	}
+	reportSourceTypes(pkg, file, t.filter, &v.reporter)
+	t.filter.keepUnselectedImports(file, t.customs, cockroachErrorsImportPath, &v.reporter)
+	if err := astio.InspectWithIgnoredCalls(pkg, file, ignored, &v); err != nil {
+		return 0, nil, fmt.Errorf("could not inspect the file: %w", err)
+	}
+	r := normalizeErrorf(pkg, file, ignored, t.filter, cockroachErrorsImportPath, "errors", "errors")
+	c := rewriteChainWalks(pkg, file, t.filter, CockroachErrors, "errors")
+	if v.needImport == 0 && r.changes == 0 && m == 0 && c == 0 && len(v.remainingCalls) == 0 && len(v.remainingRefs) == 0 && len(v.remainingMethods) == 0 {
+		return 0, v.diagnostics, nil
+	}
+	n := v.addSentinelImport(pkg, file)
+	n += t.replaceImports(pkg, file, v.needImport+c, v.remainingImportPaths())
+	n += keepRemainingCalls(pkg, file, &v.reporter)
+	return v.needImport + r.changes + m + c + n, v.diagnostics, nil
+}
+
+func (t *toCockroachErrors) replaceImports(pkg *packages.Package, file *ast.File, needImport int, keepImports map[string]bool) int {
+	var n int
+	if needImport > 0 {
+		if astutil.AddImport(pkg.Fset, file, cockroachErrorsImportPath) {
+			n++
+			log.Printf("%s: + import %s", astio.Filename(pkg, file), cockroachErrorsImportPath)
+		}
+	}
+	if !keepImports[pkgErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, pkgErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), pkgErrorsImportPath)
+	}
+	if !keepImports[xerrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, xerrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), xerrorsImportPath)
+	}
+	if !keepImports[jujuErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, jujuErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), jujuErrorsImportPath)
+	}
+	if !keepImports[githubGoErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, githubGoErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), githubGoErrorsImportPath)
+	}
+	if !keepImports[erisImportPath] && astutil.DeleteImport(pkg.Fset, file, erisImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), erisImportPath)
+	}
+	n += t.customs.deleteImports(pkg, file, keepImports)
+	if !keepImports["errors"] && astutil.DeleteImport(pkg.Fset, file, "errors") {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), "errors")
+	}
+	if !astutil.UsesImport(file, "fmt") {
+		if astutil.DeleteImport(pkg.Fset, file, "fmt") {
+			n++
+			log.Printf("%s: - import %s", astio.Filename(pkg, file), "fmt")
+		}
+	}
+	ast.SortImports(pkg.Fset, file)
+	return n
+}
+
+// toCockroachErrorsVisitor rewrites the function calls in the same way as toPkgErrorsVisitor,
+// because github.com/cockroachdb/errors provides the compatible API with github.com/pkg/errors.
+type toCockroachErrorsVisitor struct {
+	toPkgErrorsVisitor
+}
+
+func (v *toCockroachErrorsVisitor) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	if skipCall(v.filter, call, &v.reporter) {
+		return nil
+	}
+	var err error
+	switch call.PackagePath() {
+	case pkgErrorsImportPath:
+		err = v.pkgErrorsFunctionCall(call)
+	case xerrorsImportPath:
+		err = v.xerrorsFunctionCall(call)
+	case "errors":
+		err = v.goErrorsFunctionCall(call)
+	case "fmt":
+		err = v.goFmtFunctionCall(call)
+	case jujuErrorsImportPath:
+		err = v.jujuErrorsFunctionCall(call, v.pkgErrorsFunctionCall)
+	case githubGoErrorsImportPath:
+		err = replaceRenamedFunctionCall(call, renameGitHubGoErrorsFunction, v.pkgErrorsFunctionCall)
+	case erisImportPath:
+		err = replaceRenamedFunctionCall(call, renameErisFunction, v.pkgErrorsFunctionCall)
+	default:
+		if v.customs.find(call.PackagePath()) != nil {
+			err = replaceRenamedFunctionCall(call, v.customs.renameFunction, v.pkgErrorsFunctionCall)
+		}
+	}
+	if err != nil {
+		v.report(call, err)
+	}
+	return nil
+}
+
+func (v *toCockroachErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall) error {
+	switch call.FunctionName() {
+	case "New", "Errorf", "Wrap", "Wrapf", "WithStack", "WithMessage", "WithMessagef", "Unwrap", "As", "Is":
+		replacePackageFunctionCall(call, "errors", "")
+		v.needImport++
+		return nil
+
+	case "Cause":
+		replacePackageFunctionCall(call, "errors", "UnwrapAll")
+		v.needImport++
+		return nil
+	}
+
+	return errUnsupportedFunction
+}
+
+func (v *toCockroachErrorsVisitor) xerrorsFunctionCall(call astio.PackageFunctionCall) error {
+	switch call.FunctionName() {
+	case "Opaque":
+		replacePackageFunctionCall(call, "errors", "")
+		v.needImport++
+		return nil
+	}
+	return v.toPkgErrorsVisitor.xerrorsFunctionCall(call)
+}
+
+// renameCockroachErrorsFunction renames the function call of github.com/cockroachdb/errors
+// to the equivalent function of github.com/pkg/errors, which is also provided by cockroachdb/errors.
+// The caller can rewrite it as a function call of github.com/pkg/errors.
+func renameCockroachErrorsFunction(call astio.PackageFunctionCall) {
+	switch call.FunctionName() {
+	case "Newf":
+		call.TargetFun.Sel.Name = "Errorf"
+	case "UnwrapAll":
+		call.TargetFun.Sel.Name = "Cause"
+	case "UnwrapOnce":
+		call.TargetFun.Sel.Name = "Unwrap"
+	}
+}
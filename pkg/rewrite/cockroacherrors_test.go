@@ -0,0 +1,72 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/int128/errto/pkg/log"
+)
+
+func TestToCockroachErrors_Transform(t *testing.T) {
+	log.Printf = t.Logf
+	var tr toCockroachErrors
+
+	t.Run("common syntax from go-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/common.go",
+			"testdata/cockroacherrors/common.go")
+	})
+	t.Run("common syntax from xerrors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/xerrors/common.go",
+			"testdata/cockroacherrors/common.go")
+	})
+	t.Run("common syntax from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/common.go",
+			"testdata/cockroacherrors/common.go")
+	})
+	t.Run("Cause from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/cause.go",
+			"testdata/cockroacherrors/cause_from_pkgerrors.go")
+	})
+	t.Run("chain walk from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/chain.go",
+			"testdata/cockroacherrors/chain_from_pkgerrors.go")
+	})
+	t.Run("formatter from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/formatter.go",
+			"testdata/cockroacherrors/formatter_from_xerrors.go")
+		// the lost detail, MessageError and the call of xerrors.FormatError() in it
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from juju-errors", func(t *testing.T) {
+		tr := toCockroachErrors{sentinels: &sentinelPackage{Path: "github.com/int128/errto/errkind"}}
+		diagnostics := transform(t, &tr,
+			"testdata/jujuerrors/specific.go",
+			"testdata/cockroacherrors/specific_from_jujuerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from github go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/githubgoerrors/specific.go",
+			"testdata/cockroacherrors/specific_from_githubgoerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from eris", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/eris/specific.go",
+			"testdata/cockroacherrors/specific_from_eris.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+}
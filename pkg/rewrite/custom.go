@@ -0,0 +1,247 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/config"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// pkgErrorsFunctionNames maps the operations to the functions of github.com/pkg/errors.
+var pkgErrorsFunctionNames = map[config.Operation]string{
+	config.New:         "New",
+	config.Errorf:      "Errorf",
+	config.Wrap:        "Wrap",
+	config.Wrapf:       "Wrapf",
+	config.WithMessage: "WithMessage",
+	config.WithStack:   "WithStack",
+	config.Unwrap:      "Unwrap",
+	config.Is:          "Is",
+	config.As:          "As",
+	config.Cause:       "Cause",
+}
+
+// pkgErrorsOperation returns the operation of the function of github.com/pkg/errors.
+func pkgErrorsOperation(name string) (config.Operation, bool) {
+	for op, n := range pkgErrorsFunctionNames {
+		if n == name {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// customPackages represents the in-house packages of error helpers declared in the config file.
+// They are rewritten in the same way as the well-known packages.
+type customPackages []config.Package
+
+func (c customPackages) find(path string) *config.Package {
+	for i := range c {
+		if c[i].Path == path {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// renameFunction renames the function call of the in-house package
+// to the function of github.com/pkg/errors which provides the same operation.
+// The caller can rewrite it as a function call of github.com/pkg/errors.
+func (c customPackages) renameFunction(call astio.PackageFunctionCall) error {
+	p := c.find(call.PackagePath())
+	if p == nil {
+		return errUnsupportedFunction
+	}
+	op, ok := p.Functions[call.FunctionName()]
+	if !ok {
+		return errUnsupportedFunction
+	}
+	call.TargetFun.Sel.Name = pkgErrorsFunctionNames[op]
+	return nil
+}
+
+// deleteImports deletes the imports of the in-house packages except the kept ones.
+// It returns the number of changes.
+func (c customPackages) deleteImports(pkg *packages.Package, file *ast.File, keepImports map[string]bool) int {
+	var n int
+	for _, p := range c {
+		if !keepImports[p.Path] && astutil.DeleteImport(pkg.Fset, file, p.Path) {
+			n++
+			log.Printf("%s: - import %s", astio.Filename(pkg, file), p.Path)
+		}
+	}
+	return n
+}
+
+// toCustom rewrites the function calls with an in-house package of error helpers.
+// A function call is rewritten to github.com/pkg/errors first,
+// and then to the function of the in-house package which provides the same operation.
+type toCustom struct {
+	target    *config.Package
+	customs   customPackages
+	filter    *callFilter
+	sentinels *sentinelPackage
+}
+
+func (t *toCustom) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
+	if pkg.Types.Path() == t.target.Path {
+		// the package cannot import itself
+		return 0, nil, nil
+	}
+	var v toCustomVisitor
+	v.target = t.target
+	v.customs = t.customs
+	v.filter = t.filter
+	v.sentinels = t.sentinels
+	// compute the ignored calls once, before the passes replace any node of the file
+	ignored := astio.IgnoredCalls(pkg, file)
+	var m int
+	if t.filter.selected(xerrorsImportPath) {
+		m += migrateXerrorsFormatters(pkg, file, &v.reporter)
+		m += migrateXerrorsFrames(pkg, file, &v.reporter)
+	}
+	m += addDelegateMethods(pkg, file, "Cause", "Unwrap", &v.reporter)
+	if t.filter.selected(jujuErrorsImportPath) {
+		m += v.replaceJujuConstErrors(pkg, file, ignored, t.filter, &v.reporter)
+	}
+	if t.filter.selected(erisImportPath) {
+		m += replaceErisToString(pkg, file, ignored, t.filter)
+	}
+	reportSourceTypes(pkg, file, t.filter, &v.reporter)
+	t.filter.keepUnselectedImports(file, t.customs, t.target.Path, &v.reporter)
+	if err := astio.InspectWithIgnoredCalls(pkg, file, ignored, &v); err != nil {
+		return 0, nil, fmt.Errorf("could not inspect the file: %w", err)
+	}
+	if v.needImport == 0 && m == 0 && len(v.remainingCalls) == 0 && len(v.remainingRefs) == 0 && len(v.remainingMethods) == 0 {
+		return 0, v.diagnostics, nil
+	}
+	n := v.addSentinelImport(pkg, file)
+	n += t.replaceImports(pkg, file, v.needImport, v.remainingImportPaths())
+	n += keepRemainingCalls(pkg, file, &v.reporter)
+	return v.needImport + m + n, v.diagnostics, nil
+}
+
+func (t *toCustom) replaceImports(pkg *packages.Package, file *ast.File, needImport int, keepImports map[string]bool) int {
+	var n int
+	if needImport > 0 {
+		if astutil.AddImport(pkg.Fset, file, t.target.Path) {
+			n++
+			log.Printf("%s: + import %s", astio.Filename(pkg, file), t.target.Path)
+		}
+	}
+	for _, path := range []string{
+		pkgErrorsImportPath,
+		xerrorsImportPath,
+		cockroachErrorsImportPath,
+		jujuErrorsImportPath,
+		githubGoErrorsImportPath,
+		erisImportPath,
+		"errors",
+	} {
+		if !keepImports[path] && astutil.DeleteImport(pkg.Fset, file, path) {
+			n++
+			log.Printf("%s: - import %s", astio.Filename(pkg, file), path)
+		}
+	}
+	keepImports[t.target.Path] = true // the target is one of the in-house packages
+	n += t.customs.deleteImports(pkg, file, keepImports)
+	if !astutil.UsesImport(file, "fmt") {
+		if astutil.DeleteImport(pkg.Fset, file, "fmt") {
+			n++
+			log.Printf("%s: - import %s", astio.Filename(pkg, file), "fmt")
+		}
+	}
+	ast.SortImports(pkg.Fset, file)
+	return n
+}
+
+// toCustomVisitor rewrites a function call to github.com/pkg/errors by toPkgErrorsVisitor,
+// and then renames it to the function of the in-house package.
+type toCustomVisitor struct {
+	toPkgErrorsVisitor
+	target *config.Package
+}
+
+// name returns the package name of the in-house package.
+// It assumes that the package name is the last element of the import path.
+func (v *toCustomVisitor) name() string {
+	return path.Base(v.target.Path)
+}
+
+func (v *toCustomVisitor) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	if skipCall(v.filter, call, &v.reporter) {
+		return nil
+	}
+	if call.PackagePath() == v.target.Path {
+		return nil
+	}
+	pkgName, name, args := call.TargetPkg.Name, call.FunctionName(), call.Args()
+	// the format string may be modified in place, e.g. Errorf("FORMAT: %w") -> Wrapf("FORMAT")
+	lits := make(map[*ast.BasicLit]string)
+	for _, arg := range args {
+		if lit, ok := arg.(*ast.BasicLit); ok {
+			lits[lit] = lit.Value
+		}
+	}
+	needImport, needSentinelImport := v.needImport, v.needSentinelImport
+	var sentinelsUsed bool
+	if v.sentinels != nil {
+		sentinelsUsed = v.sentinels.used
+	}
+	if call.PackagePath() == pkgErrorsImportPath {
+		if err := v.pkgErrorsFunctionCall(call); err != nil {
+			v.report(call, err)
+			return nil
+		}
+	} else if err := v.toPkgErrorsVisitor.PackageFunctionCall(call); err != nil {
+		return err
+	}
+	if v.needImport == needImport {
+		// not rewritten, or reported by toPkgErrorsVisitor
+		return nil
+	}
+	err := v.customFunctionCall(call)
+	if err == nil {
+		return nil
+	}
+	call.TargetPkg.Name = pkgName
+	call.TargetFun.Sel.Name = name
+	call.SetArgs(args)
+	for lit, value := range lits {
+		lit.Value = value
+	}
+	v.needImport, v.needSentinelImport = needImport, needSentinelImport
+	if v.sentinels != nil {
+		v.sentinels.used = sentinelsUsed
+	}
+	switch call.PackagePath() {
+	case "errors", "fmt":
+		// the standard library can be left as it is, unless the package name conflicts
+		if v.name() != call.PackagePath() {
+			v.keepImport(call.PackagePath())
+			return nil
+		}
+	}
+	v.report(call, err)
+	return nil
+}
+
+// customFunctionCall renames the function call of github.com/pkg/errors
+// to the function of the in-house package which provides the same operation.
+func (v *toCustomVisitor) customFunctionCall(call astio.PackageFunctionCall) error {
+	op, ok := pkgErrorsOperation(call.FunctionName())
+	if !ok {
+		return errUnsupportedFunction
+	}
+	fn := v.target.Function(op)
+	if fn == "" {
+		return fmt.Errorf("no function of %s is mapped to %s", v.target.Path, op)
+	}
+	replacePackageFunctionCall(call, v.name(), fn)
+	return nil
+}
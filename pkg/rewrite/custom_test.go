@@ -0,0 +1,70 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/int128/errto/pkg/config"
+	"github.com/int128/errto/pkg/log"
+)
+
+var testCustomPackage = config.Package{
+	Path: "github.com/int128/errto/pkg/rewrite/testdata/custom/errs",
+	Functions: map[string]config.Operation{
+		"New":    config.New,
+		"Newf":   config.Errorf,
+		"Wrap":   config.Wrap,
+		"Wrapf":  config.Wrapf,
+		"Unwrap": config.Unwrap,
+		"As":     config.As,
+		"Is":     config.Is,
+	},
+}
+
+func TestToCustom_Transform(t *testing.T) {
+	log.Printf = t.Logf
+	tr := toCustom{
+		target:  &testCustomPackage,
+		customs: customPackages{testCustomPackage},
+	}
+
+	t.Run("common syntax from go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/goerrors/common.go",
+			"testdata/custom/common_from_goerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("common syntax from pkg-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/common.go",
+			"testdata/custom/common_from_pkgerrors.go")
+		if len(diagnostics) != 5 {
+			t.Errorf("len(diagnostics) wants 5 but was %d", len(diagnostics))
+		}
+	})
+}
+
+func TestCustomPackages(t *testing.T) {
+	log.Printf = t.Logf
+	customs := customPackages{testCustomPackage}
+
+	t.Run("to go-errors", func(t *testing.T) {
+		tr := toGoErrors{customs: customs}
+		diagnostics := transform(t, &tr,
+			"testdata/custom/specific.go",
+			"testdata/goerrors/specific_from_custom.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("to pkg-errors", func(t *testing.T) {
+		tr := toPkgErrors{customs: customs}
+		diagnostics := transform(t, &tr,
+			"testdata/custom/specific.go",
+			"testdata/pkgerrors/specific_from_custom.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+}
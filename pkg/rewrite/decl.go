@@ -0,0 +1,135 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// newFuncDecl parses the source of a function declaration.
+// All the positions are set to pos, which should be the end of the preceding declaration,
+// so that the declaration can be inserted into any file.
+// The signature is placed two lines after pos, so that the printer puts a blank line before the declaration.
+// The body is kept at pos, so that the blank line after the declaration is kept as well.
+func newFuncDecl(fset *token.FileSet, src string, pos token.Pos) *ast.FuncDecl {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package main\n\n"+src, 0)
+	if err != nil {
+		panic(err)
+	}
+	decl := f.Decls[0].(*ast.FuncDecl)
+	setPositions(decl, lineAfter(fset, pos, 2, len(src)))
+	setPositions(decl.Body, pos)
+	return decl
+}
+
+// lineAfter returns the position of the same offset as pos but n lines after it.
+// The printer computes the blank lines from the line numbers and the order of comments from the offsets,
+// so a node at the position is separated by blank lines without moving the comments around it.
+// The position belongs to a new file of the same name in the file set,
+// which has size bytes after the position so that the end positions of the node are in the same line.
+// It returns pos if the offset is too small to have the lines.
+func lineAfter(fset *token.FileSet, pos token.Pos, n, size int) token.Pos {
+	p := fset.PositionFor(pos, false)
+	if !p.IsValid() || p.Offset < p.Line+n-1 {
+		return pos
+	}
+	f := fset.AddFile(p.Filename, -1, p.Offset+size+1)
+	lines := make([]int, p.Line+n)
+	for i := range lines {
+		lines[i] = i
+	}
+	if !f.SetLines(lines) {
+		return pos
+	}
+	return f.Pos(p.Offset)
+}
+
+// newStmt parses the source of a statement.
+// All the positions are set to pos, which should be the position of the replaced statement.
+func newStmt(src string, pos token.Pos) ast.Stmt {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package main\n\nfunc _() {\n"+src+"\n}\n", 0)
+	if err != nil {
+		panic(err)
+	}
+	stmt := f.Decls[0].(*ast.FuncDecl).Body.List[0]
+	setPositions(stmt, pos)
+	return stmt
+}
+
+// mergeLines merges the lines between start and end into one line,
+// so that a statement can be replaced with a shorter one without blank lines.
+// The nodes in the range must be removed.
+func mergeLines(fset *token.FileSet, start, end token.Pos) {
+	f := fset.File(start)
+	first, last := f.Line(start), f.Line(end)
+	for i := first; i < last; i++ {
+		f.MergeLine(first)
+	}
+}
+
+// insertDeclAfter inserts the declaration after the existing one.
+func insertDeclAfter(file *ast.File, after ast.Decl, decl ast.Decl) {
+	for i, d := range file.Decls {
+		if d == after {
+			var decls []ast.Decl
+			decls = append(decls, file.Decls[:i+1]...)
+			decls = append(decls, decl)
+			decls = append(decls, file.Decls[i+1:]...)
+			file.Decls = decls
+			return
+		}
+	}
+	file.Decls = append(file.Decls, decl)
+}
+
+// copyExpr returns a deep copy of the expression without the positions.
+func copyExpr(fset *token.FileSet, expr ast.Expr) ast.Expr {
+	newExpr, err := parser.ParseExpr(exprString(fset, expr))
+	if err != nil {
+		panic(fmt.Sprintf("could not parse the expression: %s", err))
+	}
+	setPositions(newExpr, token.NoPos)
+	return newExpr
+}
+
+// setPositions sets all the valid positions in the node to pos.
+// A position in the comments of the node would break the order of comments,
+// so it is recommended to set the end of the preceding node.
+func setPositions(node ast.Node, pos token.Pos) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		v := reflect.ValueOf(node).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Type() == reflect.TypeOf(token.NoPos) && token.Pos(f.Int()).IsValid() {
+				f.Set(reflect.ValueOf(pos))
+			}
+		}
+		if ident, ok := node.(*ast.Ident); ok {
+			ident.Obj = nil
+		}
+		return true
+	})
+}
+
+func copyIdents(idents []*ast.Ident) []*ast.Ident {
+	var newIdents []*ast.Ident
+	for _, ident := range idents {
+		newIdents = append(newIdents, ast.NewIdent(ident.Name))
+	}
+	return newIdents
+}
+
+// exprString returns the source of the expression.
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var b strings.Builder
+	if err := printer.Fprint(&b, fset, expr); err != nil {
+		panic(fmt.Sprintf("could not print the expression: %s", err))
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package rewrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"sort"
+
+	"github.com/int128/errto/pkg/astio"
+	"golang.org/x/tools/go/packages"
+)
+
+// Usage represents the number of function calls of each library.
+// The key is the name of the library accepted by the migrate command,
+// i.e. a method such as pkg-errors or an import path such as github.com/juju/errors.
+type Usage map[string]int
+
+// Total returns the number of function calls of all libraries.
+func (u Usage) Total() int {
+	var n int
+	for _, c := range u {
+		n += c
+	}
+	return n
+}
+
+// Libraries returns the names of the libraries in descending order of the number of function calls.
+func (u Usage) Libraries() []string {
+	var names []string
+	for name := range u {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if u[names[i]] != u[names[j]] {
+			return u[names[i]] > u[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (u Usage) add(other Usage) {
+	for name, c := range other {
+		u[name] += c
+	}
+}
+
+// Detection represents the usage of the error libraries in the packages.
+type Detection struct {
+	Modules []*ModuleUsage
+	Target  string // recommended target for all modules
+}
+
+// ModuleUsage represents the usage of the error libraries in a module.
+type ModuleUsage struct {
+	Module   *astio.Module // nil if go.mod is not found
+	Usage    Usage
+	Packages []PackageUsage
+	Target   string // recommended target, e.g. pkg-errors
+}
+
+func (mu *ModuleUsage) addPackage(path string, u Usage) {
+	for _, p := range mu.Packages {
+		if p.Path == path {
+			p.Usage.add(u)
+			return
+		}
+	}
+	mu.Packages = append(mu.Packages, PackageUsage{Path: path, Usage: u})
+}
+
+// PackageUsage represents the usage of the error libraries in a package.
+type PackageUsage struct {
+	Path  string
+	Usage Usage
+}
+
+// Detect counts the function calls of the error libraries in the packages,
+// and recommends the target which minimizes the changes.
+// It loads the packages in the same way as Do, that is, it considers the modules,
+// the build tags, the platforms, the excluded files and the broken packages of the input.
+func Detect(ctx context.Context, in Input) (*Detection, error) {
+	configs, err := buildConfigs(in.Tags, in.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	files, err := newFileFilter(in.ExcludePatterns, in.IncludeGenerated)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude patterns: %w", err)
+	}
+	dirs, err := moduleDirs(in.AllModules)
+	if err != nil {
+		return nil, err
+	}
+	load := astio.LoadWithConfig
+	if in.KeepGoing {
+		load = astio.LoadPartially
+	}
+	var broken brokenPackages
+	var pkgs []*packages.Package
+	for _, dir := range dirs {
+		for _, c := range configs {
+			loaded, err := load(ctx, c, dir, in.PkgNames...)
+			if err != nil {
+				return nil, fmt.Errorf("could not load the packages: %w", err)
+			}
+			pkgs = append(pkgs, broken.filter(loaded)...)
+		}
+	}
+	if len(pkgs) == 0 && len(broken.ids) == 0 {
+		return nil, errors.New("no package found")
+	}
+	d := detect(pkgs, customPackages(in.CustomPackages), files)
+	files.printSummary()
+	broken.printSummary()
+	return d, nil
+}
+
+// detect counts the function calls in the selected variants of the files,
+// except the files skipped by the file filter.
+func detect(pkgs []*packages.Package, customs customPackages, files *fileFilter) *Detection {
+	var d Detection
+	modules := make(map[string]*ModuleUsage)
+	selected := selectVariants(pkgs)
+	for _, pkg := range pkgs {
+		if len(pkg.CompiledGoFiles) == 0 || !hasSelectedFile(pkg, selected) {
+			continue
+		}
+		m := findModule(filepath.Dir(pkg.CompiledGoFiles[0]))
+		var key string
+		if m != nil {
+			key = m.Dir
+		}
+		mu := modules[key]
+		if mu == nil {
+			mu = &ModuleUsage{Module: m, Usage: make(Usage)}
+			modules[key] = mu
+			d.Modules = append(d.Modules, mu)
+		}
+		u := make(Usage)
+		for _, file := range pkg.Syntax {
+			if !selected[file] || files.skip(pkg, file) {
+				continue
+			}
+			countCalls(pkg, file, customs, u)
+		}
+		if len(u) == 0 {
+			continue
+		}
+		mu.Usage.add(u)
+		mu.addPackage(pkg.Types.Path(), u)
+	}
+	total := make(Usage)
+	allowGoErrors := true
+	for _, mu := range d.Modules {
+		sort.Slice(mu.Packages, func(i, j int) bool { return mu.Packages[i].Path < mu.Packages[j].Path })
+		mu.Target = recommendTarget(mu.Usage, canWrapWithGoErrors(mu.Module), customs)
+		total.add(mu.Usage)
+		allowGoErrors = allowGoErrors && canWrapWithGoErrors(mu.Module)
+	}
+	d.Target = recommendTarget(total, allowGoErrors, customs)
+	return &d
+}
+
+// canWrapWithGoErrors returns true if the module can use fmt.Errorf("%w") of Go 1.13.
+// If the go directive is not given, it assumes the current Go version.
+func canWrapWithGoErrors(m *astio.Module) bool {
+	return m == nil || m.GoVersion == "" || m.GoVersionAtLeast(1, 13)
+}
+
+// countCalls counts the function calls of the error libraries in the file.
+func countCalls(pkg *packages.Package, file *ast.File, customs customPackages, u Usage) {
+	// the callback never returns an error
+	_ = astio.Inspect(pkg, file, callCounter(func(call astio.PackageFunctionCall) error {
+		if name := libraryName(call, customs); name != "" {
+			u[name]++
+		}
+		return nil
+	}))
+}
+
+type callCounter func(call astio.PackageFunctionCall) error
+
+func (f callCounter) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	return f(call)
+}
+
+// libraryName returns the name of the library of the function call.
+// It returns an empty string if the function call is not of an error library.
+func libraryName(call astio.PackageFunctionCall, customs customPackages) string {
+	switch path := call.PackagePath(); path {
+	case "errors":
+		return GoErrors.String()
+	case "fmt":
+		if call.FunctionName() == "Errorf" {
+			return GoErrors.String()
+		}
+	case xerrorsImportPath:
+		return Xerrors.String()
+	case pkgErrorsImportPath:
+		return PkgErrors.String()
+	case cockroachErrorsImportPath:
+		return CockroachErrors.String()
+	case jujuErrorsImportPath,
+		githubGoErrorsImportPath,
+		erisImportPath,
+		multierrImportPath,
+		multierrorImportPath,
+		utilerrorsImportPath:
+		return path
+	default:
+		if customs.find(path) != nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// recommendTarget returns the target which minimizes the changes,
+// i.e. the library which has the most function calls.
+// go-errors is preferred if the numbers are same, and excluded if it is not allowed.
+func recommendTarget(u Usage, allowGoErrors bool, customs customPackages) string {
+	var candidates []string
+	if allowGoErrors {
+		candidates = append(candidates, GoErrors.String())
+	}
+	candidates = append(candidates, Xerrors.String(), PkgErrors.String(), CockroachErrors.String())
+	for _, p := range customs {
+		candidates = append(candidates, p.Path)
+	}
+	var target string
+	for _, c := range candidates {
+		if target == "" || u[c] > u[target] {
+			target = c
+		}
+	}
+	return target
+}
+
+// parseTarget returns the method and the in-house package of the target.
+// The target is either a method or an import path of the in-house package.
+func parseTarget(target string, customs customPackages) (Method, string, error) {
+	if customs.find(target) != nil {
+		return Custom, target, nil
+	}
+	m, err := ParseMethod(target)
+	if err != nil {
+		return 0, "", err
+	}
+	return m, "", nil
+}
@@ -0,0 +1,84 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/int128/errto/pkg/astio"
+)
+
+func TestRecommendTarget(t *testing.T) {
+	customs := customPackages{testCustomPackage}
+	for _, c := range []struct {
+		name          string
+		usage         Usage
+		allowGoErrors bool
+		want          string
+	}{
+		{
+			name:          "no call",
+			allowGoErrors: true,
+			want:          "go-errors",
+		},
+		{
+			name:          "no call before Go 1.13",
+			allowGoErrors: false,
+			want:          "xerrors",
+		},
+		{
+			name:          "pkg-errors is dominant",
+			usage:         Usage{"go-errors": 3, "pkg-errors": 5, jujuErrorsImportPath: 10},
+			allowGoErrors: true,
+			want:          "pkg-errors",
+		},
+		{
+			name:          "same number",
+			usage:         Usage{"go-errors": 5, "pkg-errors": 5},
+			allowGoErrors: true,
+			want:          "go-errors",
+		},
+		{
+			name:          "go-errors before Go 1.13",
+			usage:         Usage{"go-errors": 5, "pkg-errors": 3},
+			allowGoErrors: false,
+			want:          "pkg-errors",
+		},
+		{
+			name:          "in-house package",
+			usage:         Usage{"go-errors": 5, testCustomPackage.Path: 8},
+			allowGoErrors: true,
+			want:          testCustomPackage.Path,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := recommendTarget(c.usage, c.allowGoErrors, customs)
+			if got != c.want {
+				t.Errorf("recommendTarget wants %s but was %s", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDetection_targetOf(t *testing.T) {
+	a := &astio.Module{Path: "example.com/a", Dir: "/src/a", GoVersion: "1.21"}
+	b := &astio.Module{Path: "example.com/b", Dir: "/src/b", GoVersion: "1.12"}
+	d := &Detection{
+		Modules: []*ModuleUsage{
+			{Module: &astio.Module{Path: "example.com/a", Dir: "/src/a"}, Target: "go-errors"},
+			{Module: b, Target: "pkg-errors"},
+		},
+		Target: "xerrors",
+	}
+	for _, c := range []struct {
+		module *astio.Module
+		want   string
+	}{
+		{a, "go-errors"},
+		{b, "pkg-errors"},
+		{&astio.Module{Path: "example.com/c", Dir: "/src/c"}, "xerrors"},
+		{nil, "xerrors"},
+	} {
+		if got := d.targetOf(c.module); got != c.want {
+			t.Errorf("targetOf(%v) wants %s but was %s", c.module, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+)
+
+// Diagnostic represents a function call which could not be rewritten.
+type Diagnostic struct {
+	Position token.Position
+	Function string // qualified name of the function, e.g. github.com/pkg/errors.Wrapf
+	Reason   string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s(): %s", d.Position, d.Function, d.Reason)
+}
+
+// reporter collects the function calls which could not be rewritten.
+// The function calls are left as they are.
+type reporter struct {
+	diagnostics      []Diagnostic
+	remainingCalls   []remainingCall
+	remainingRefs    []remainingRef
+	remainingMethods []remainingMethod
+	remainingImports map[string]bool
+
+	skippedCalls      []astio.PackageFunctionCall // function calls which are not selected by the filter
+	unselectedImports map[string]bool             // imports of the packages which are not selected as the source
+}
+
+type remainingCall struct {
+	call   astio.PackageFunctionCall
+	reason error
+}
+
+// remainingRef is a qualified identifier which is not a function call, e.g. errors.Error.
+type remainingRef struct {
+	sel    *ast.SelectorExpr
+	pkg    *ast.Ident // the package name of sel
+	path   string
+	reason error
+}
+
+// remainingMethod is a method call which is not available in the target, e.g. e.ErrorStack().
+type remainingMethod struct {
+	sel    *ast.SelectorExpr
+	reason error
+}
+
+// report adds a diagnostic of the function call.
+// The function call is left as it is.
+func (r *reporter) report(call astio.PackageFunctionCall, reason error) {
+	r.add(Diagnostic{
+		Position: call.Position,
+		Function: call.PackagePath() + "." + call.FunctionName(),
+		Reason:   reason.Error(),
+	})
+	r.remainingCalls = append(r.remainingCalls, remainingCall{call: call, reason: reason})
+}
+
+// reportRef adds a diagnostic of the qualified identifier of the package.
+// The identifier is left as it is.
+func (r *reporter) reportRef(position token.Position, sel *ast.SelectorExpr, path string, reason error) {
+	r.add(Diagnostic{
+		Position: position,
+		Function: path + "." + sel.Sel.Name,
+		Reason:   reason.Error(),
+	})
+	r.remainingRefs = append(r.remainingRefs, remainingRef{sel: sel, pkg: sel.X.(*ast.Ident), path: path, reason: reason})
+}
+
+// reportMethod adds a diagnostic of the method call.
+// The method call is left as it is.
+func (r *reporter) reportMethod(position token.Position, sel *ast.SelectorExpr, function string, reason error) {
+	r.add(Diagnostic{
+		Position: position,
+		Function: function,
+		Reason:   reason.Error(),
+	})
+	r.remainingMethods = append(r.remainingMethods, remainingMethod{sel: sel, reason: reason})
+}
+
+// add adds the diagnostic.
+func (r *reporter) add(diagnostic Diagnostic) {
+	log.Printf("%s: NOTE: %s(): %s", diagnostic.Position, diagnostic.Function, diagnostic.Reason)
+	r.diagnostics = append(r.diagnostics, diagnostic)
+}
+
+// skip leaves the function call as it is, without any diagnostic.
+func (r *reporter) skip(call astio.PackageFunctionCall) {
+	r.skippedCalls = append(r.skippedCalls, call)
+	r.keepImport(call.PackagePath())
+}
+
+// IgnoredPackageFunctionCall leaves the function call marked by the ignore directive as it is.
+func (r *reporter) IgnoredPackageFunctionCall(call astio.PackageFunctionCall) {
+	log.Printf("%s: %s.%s() is ignored", call.Position, call.TargetPkg.Name, call.FunctionName())
+	r.skip(call)
+}
+
+// keepImport marks the import path as still used by the code which is left as it is.
+func (r *reporter) keepImport(path string) {
+	if r.remainingImports == nil {
+		r.remainingImports = make(map[string]bool)
+	}
+	r.remainingImports[path] = true
+}
+
+// remainingImportPaths returns the import paths which are still used by the remaining code.
+func (r *reporter) remainingImportPaths() map[string]bool {
+	paths := make(map[string]bool)
+	for _, c := range r.remainingCalls {
+		paths[c.call.PackagePath()] = true
+	}
+	for _, ref := range r.remainingRefs {
+		paths[ref.path] = true
+	}
+	for path := range r.remainingImports {
+		paths[path] = true
+	}
+	return paths
+}
+
+var errUnsupportedFunction = errors.New("no equivalent function in the target")
@@ -0,0 +1,74 @@
+package rewrite
+
+import (
+	"errors"
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// renameErisFunction checks the function call of github.com/rotisserie/eris,
+// which provides the compatible API with github.com/pkg/errors.
+// The caller can rewrite it as a function call of github.com/pkg/errors.
+func renameErisFunction(call astio.PackageFunctionCall) error {
+	switch call.FunctionName() {
+	case "New", "Errorf", "Wrap", "Wrapf", "Cause", "Unwrap", "As", "Is":
+		return nil
+	case "ToString", "ToJSON", "StackFrames":
+		return errors.New("the stack trace is not available in the target")
+	}
+	return errUnsupportedFunction
+}
+
+// replaceErisToString rewrites eris.ToString() without the stack trace to Error() of the error.
+//
+//	eris.ToString(err, false) -> err.Error()
+//
+// This must be called before the visitor, because the result is not a package function call.
+// A call marked by the ignore directive or not selected by the filter is left as it is.
+// It returns the number of changes.
+func replaceErisToString(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, filter *callFilter) int {
+	if !filter.selectedFunction(erisImportPath, "ToString") {
+		return 0
+	}
+	var n int
+	astutil.Apply(file, func(c *astutil.Cursor) bool {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 || ignored[call] {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || fun.Sel.Name != "ToString" {
+			return true
+		}
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName)
+		if !ok || pkgName.Imported().Path() != erisImportPath {
+			return true
+		}
+		tv, ok := pkg.TypesInfo.Types[call.Args[1]]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.Bool || constant.BoolVal(tv.Value) {
+			return true
+		}
+		log.Printf("%s: %s.ToString() -> Error()", astio.Position(pkg, call), x.Name)
+		c.Replace(&ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   call.Args[0],
+				Sel: &ast.Ident{NamePos: call.Args[0].End(), Name: "Error"},
+			},
+			Lparen: call.Rparen,
+			Rparen: call.Rparen,
+		})
+		n++
+		return false
+	}, nil)
+	return n
+}
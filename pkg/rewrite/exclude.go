@@ -0,0 +1,85 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"strings"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// fileFilter excludes the generated files and the files which match the patterns.
+// It records the skipped files for the summary.
+type fileFilter struct {
+	patterns         []string
+	includeGenerated bool
+
+	generated map[string]bool // skipped generated files
+	excluded  map[string]bool // skipped files by the patterns
+}
+
+func newFileFilter(patterns []string, includeGenerated bool) (*fileFilter, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+	}
+	return &fileFilter{
+		patterns:         patterns,
+		includeGenerated: includeGenerated,
+		generated:        make(map[string]bool),
+		excluded:         make(map[string]bool),
+	}, nil
+}
+
+// skip returns true if the file should not be rewritten.
+func (f *fileFilter) skip(pkg *packages.Package, file *ast.File) bool {
+	name := astio.Filename(pkg, file)
+	if f.generated[name] || f.excluded[name] {
+		return true
+	}
+	if !f.includeGenerated && astio.IsGenerated(file) {
+		log.Printf("--- skipped the generated file %s", name)
+		f.generated[name] = true
+		return true
+	}
+	if pattern := f.match(name); pattern != "" {
+		log.Printf("--- skipped %s by the pattern %s", name, pattern)
+		f.excluded[name] = true
+		return true
+	}
+	return false
+}
+
+// match returns the pattern which matches the filename, or an empty string.
+// A pattern matches any consecutive elements of the path,
+// e.g. *_test.go, internal/gen/*.go or testdata.
+func (f *fileFilter) match(name string) string {
+	elems := strings.Split(filepath.ToSlash(name), "/")
+	var candidates []string
+	for i := range elems {
+		for j := i + 1; j <= len(elems); j++ {
+			candidates = append(candidates, strings.Join(elems[i:j], "/"))
+		}
+	}
+	for _, pattern := range f.patterns {
+		pattern = filepath.ToSlash(pattern)
+		for _, c := range candidates {
+			if ok, _ := filepath.Match(pattern, c); ok {
+				return pattern
+			}
+		}
+	}
+	return ""
+}
+
+// printSummary shows the number of the skipped files.
+func (f *fileFilter) printSummary() {
+	if len(f.generated) == 0 && len(f.excluded) == 0 {
+		return
+	}
+	log.Printf("--- skipped %d generated file(s) and %d excluded file(s)", len(f.generated), len(f.excluded))
+}
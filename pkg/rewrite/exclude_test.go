@@ -0,0 +1,31 @@
+package rewrite
+
+import (
+	"testing"
+)
+
+func TestFileFilter_match(t *testing.T) {
+	f, err := newFileFilter([]string{"*_test.go", "internal/gen/*.go", "testdata"}, false)
+	if err != nil {
+		t.Fatalf("newFileFilter error: %s", err)
+	}
+	for _, c := range []struct {
+		name string
+		want string
+	}{
+		{"main.go", ""},
+		{"pkg/foo/foo_test.go", "*_test.go"},
+		{"internal/gen/foo.go", "internal/gen/*.go"},
+		{"pkg/internal/gen/foo.go", "internal/gen/*.go"},
+		{"pkg/internal/gen/sub/foo.go", ""},
+		{"pkg/testdata/foo.go", "testdata"},
+		{"pkg/testdata2/foo.go", ""},
+	} {
+		if got := f.match(c.name); got != c.want {
+			t.Errorf("match(%s) wants %q but was %q", c.name, c.want, got)
+		}
+	}
+	if _, err := newFileFilter([]string{"["}, false); err == nil {
+		t.Errorf("newFileFilter wants an error for an invalid pattern")
+	}
+}
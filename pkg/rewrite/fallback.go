@@ -0,0 +1,208 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// keepRemainingCalls keeps the function calls and identifiers which could not be rewritten,
+// so that the file can be compiled in the middle of migration.
+// The imports of them must not be deleted by the transformer.
+//
+// If the package name of a remaining import conflicts with another import,
+// it aliases the import, the function calls and the identifiers.
+// So does it for the function calls and imports which are not selected by the filter.
+// It adds a TODO comment to each function call and identifier.
+// It returns the number of changes.
+func keepRemainingCalls(pkg *packages.Package, file *ast.File, r *reporter) int {
+	n := aliasUnselectedImports(pkg, file, r)
+	var paths []string
+	for _, c := range r.remainingCalls {
+		paths = append(paths, c.call.PackagePath())
+	}
+	for _, call := range r.skippedCalls {
+		paths = append(paths, call.PackagePath())
+	}
+	for _, ref := range r.remainingRefs {
+		paths = append(paths, ref.path)
+	}
+	sort.Strings(paths)
+	for i, path := range paths {
+		if i > 0 && paths[i-1] == path {
+			continue
+		}
+		if aliasRemainingImport(pkg, file, path, r) {
+			n++
+		}
+	}
+	for _, c := range r.remainingCalls {
+		addTODOComment(file, c.call.Call,
+			fmt.Sprintf("rewrite %s.%s() manually: %s", c.call.TargetPkg.Name, c.call.FunctionName(), c.reason))
+		n++
+	}
+	for _, ref := range r.remainingRefs {
+		addTODOComment(file, ref.sel,
+			fmt.Sprintf("rewrite %s.%s manually: %s", ref.pkg.Name, ref.sel.Sel.Name, ref.reason))
+		n++
+	}
+	for _, m := range r.remainingMethods {
+		addTODOComment(file, m.sel,
+			fmt.Sprintf("rewrite %s() manually: %s", types.ExprString(m.sel), m.reason))
+		n++
+	}
+	return n
+}
+
+func aliasRemainingImport(pkg *packages.Package, file *ast.File, path string, r *reporter) bool {
+	spec := conflictingImport(pkg, file, path)
+	if spec == nil {
+		return false
+	}
+	alias := importAlias(path)
+	spec.Name = ast.NewIdent(alias)
+	for _, c := range r.remainingCalls {
+		if c.call.PackagePath() == path {
+			c.call.TargetPkg.Name = alias
+			aliasArgRefs(pkg, c.call.Call, path, alias)
+		}
+	}
+	for _, ref := range r.remainingRefs {
+		if ref.path == path {
+			ref.pkg.Name = alias
+		}
+	}
+	for _, call := range r.skippedCalls {
+		if call.PackagePath() == path {
+			call.TargetPkg.Name = alias
+			aliasArgRefs(pkg, call.Call, path, alias)
+		}
+	}
+	log.Printf("%s: import %s as %s", astio.Filename(pkg, file), path, alias)
+	return true
+}
+
+// aliasArgRefs renames the references to the constants, variables and types of the package
+// in the arguments of the function call left as it is, e.g. errors.NotFound of github.com/juju/errors.
+// The function calls in the arguments are renamed by their own entries.
+func aliasArgRefs(pkg *packages.Package, call *ast.CallExpr, path, alias string) {
+	for _, arg := range call.Args {
+		ast.Inspect(arg, func(node ast.Node) bool {
+			sel, ok := node.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := pkg.TypesInfo.Uses[sel.Sel]
+			if _, ok := obj.(*types.Func); ok || obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != path {
+				return true
+			}
+			if _, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); ok {
+				x.Name = alias
+			}
+			return true
+		})
+	}
+}
+
+// conflictingImport returns the import of the path if the package name conflicts with another import.
+// It returns nil if the path is not imported or the name does not conflict.
+func conflictingImport(pkg *packages.Package, file *ast.File, path string) *ast.ImportSpec {
+	var spec *ast.ImportSpec
+	otherNames := make(map[string]bool)
+	for _, s := range file.Imports {
+		if importPath(s) == path {
+			spec = s
+			continue
+		}
+		otherNames[importName(pkg, s)] = true
+	}
+	if spec == nil || !otherNames[importName(pkg, spec)] {
+		return nil
+	}
+	return spec
+}
+
+// addTODOComment adds the comment to the statement or declaration which encloses the node.
+// The statement must be in a block, i.e., not the init statement of if or switch.
+func addTODOComment(file *ast.File, target ast.Node, text string) {
+	node := target
+	path, _ := astutil.PathEnclosingInterval(file, target.Pos(), target.End())
+	for i, p := range path {
+		if _, ok := p.(ast.Stmt); ok && i+1 < len(path) && isStmtList(path[i+1]) {
+			node = p
+			break
+		}
+		if _, ok := p.(ast.Decl); ok {
+			node = p
+			break
+		}
+	}
+	comment := &ast.Comment{
+		Slash: node.Pos() - 1,
+		Text:  "// TODO(errto): " + text,
+	}
+	file.Comments = append(file.Comments, &ast.CommentGroup{List: []*ast.Comment{comment}})
+	sort.SliceStable(file.Comments, func(i, j int) bool {
+		return file.Comments[i].Pos() < file.Comments[j].Pos()
+	})
+}
+
+// isStmtList returns true if the node has a list of statements.
+func isStmtList(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// importName returns the package name of the import.
+// If the import has been added by the transformer, it guesses the name from the path.
+func importName(pkg *packages.Package, spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	if pkgName, ok := pkg.TypesInfo.Implicits[spec].(*types.PkgName); ok {
+		return pkgName.Name()
+	}
+	path := importPath(spec)
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// importAlias returns an alias of the import path, e.g. pkgerrors for github.com/pkg/errors.
+func importAlias(path string) string {
+	elems := strings.Split(path, "/")
+	if len(elems) == 1 {
+		return "go" + path
+	}
+	var b strings.Builder
+	for _, elem := range elems[len(elems)-2:] {
+		for _, r := range elem {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToLower(r))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package rewrite
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// formatVerb represents a verb in a format string, such as %s or %+v.
+type formatVerb struct {
+	Start int // offset of the leading %
+	End   int // offset after the verb character
+	Verb  rune
+	Flags string // flags, width and precision between % and the verb
+}
+
+// parseFormat returns the verbs in the format string.
+// It returns false if the format contains an explicit argument index or a star,
+// because the arguments cannot be mapped to the verbs statically.
+func parseFormat(format string) ([]formatVerb, bool) {
+	var verbs []formatVerb
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) {
+			return nil, false
+		}
+		if format[i] == '[' || format[i] == '*' {
+			return nil, false
+		}
+		if format[i] == '%' && i == start+1 {
+			continue
+		}
+		verbs = append(verbs, formatVerb{
+			Start: start,
+			End:   i + 1,
+			Verb:  rune(format[i]),
+			Flags: format[start+1 : i],
+		})
+	}
+	return verbs, true
+}
+
+// stringLiteral returns the value of the string literal.
+func stringLiteral(expr ast.Expr) (string, bool) {
+	b, ok := expr.(*ast.BasicLit)
+	if !ok || b.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(b.Value)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// newStringLiteral returns a string literal of the value.
+func newStringLiteral(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// packageFunctionCallOf returns the call expression if expr is a call of the package function.
+func packageFunctionCallOf(info *types.Info, expr ast.Expr, pkgPath, funName string) (*ast.CallExpr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || fun.Sel.Name != funName {
+		return nil, false
+	}
+	x, ok := fun.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	pkgName, ok := info.ObjectOf(x).(*types.PkgName)
+	if !ok || pkgName.Imported().Path() != pkgPath {
+		return nil, false
+	}
+	return call, true
+}
+
+// sprintfArgs returns the arguments if expr is a call of fmt.Sprintf with a literal format.
+func sprintfArgs(info *types.Info, expr ast.Expr) ([]ast.Expr, bool) {
+	call, ok := packageFunctionCallOf(info, expr, "fmt", "Sprintf")
+	if !ok {
+		return nil, false
+	}
+	if call.Ellipsis.IsValid() || len(call.Args) == 0 {
+		return nil, false
+	}
+	format, ok := stringLiteral(call.Args[0])
+	if !ok {
+		return nil, false
+	}
+	if verbs, ok := parseFormat(format); !ok || len(verbs) != len(call.Args)-1 {
+		return nil, false
+	}
+	return call.Args, true
+}
+
+// inlineSprintf folds the fmt.Sprintf calls passed to the plain %s or %v verbs into the format.
+// For example, it rewrites the arguments of
+// Errorf("%s: %w", fmt.Sprintf("FORMAT %d", x), err) to Errorf("FORMAT %d: %w", x, err).
+// args must be the arguments of an Errorf-style function, i.e., the format followed by the operands.
+func inlineSprintf(info *types.Info, args []ast.Expr) []ast.Expr {
+	if len(args) < 2 {
+		return args
+	}
+	format, ok := stringLiteral(args[0])
+	if !ok {
+		return args
+	}
+	verbs, ok := parseFormat(format)
+	if !ok || len(verbs) != len(args)-1 {
+		return args
+	}
+	var b strings.Builder
+	var newArgs []ast.Expr
+	var last int
+	var changed bool
+	for i, verb := range verbs {
+		operand := args[i+1]
+		if verb.Flags == "" && (verb.Verb == 's' || verb.Verb == 'v') {
+			if innerArgs, ok := sprintfArgs(info, operand); ok {
+				innerFormat, _ := stringLiteral(innerArgs[0])
+				b.WriteString(format[last:verb.Start])
+				b.WriteString(innerFormat)
+				last = verb.End
+				newArgs = append(newArgs, innerArgs[1:]...)
+				changed = true
+				continue
+			}
+		}
+		newArgs = append(newArgs, operand)
+	}
+	if !changed {
+		return args
+	}
+	b.WriteString(format[last:])
+	return append([]ast.Expr{newStringLiteral(b.String())}, newArgs...)
+}
+
+var errorInterface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// stripErrorCalls replaces err.Error() passed to the plain %s or %v verbs with err.
+// It returns the new arguments and the number of replaced calls.
+// args must be the arguments of an Errorf-style function, i.e., the format followed by the operands.
+func stripErrorCalls(info *types.Info, args []ast.Expr) ([]ast.Expr, int) {
+	if len(args) < 2 {
+		return args, 0
+	}
+	format, ok := stringLiteral(args[0])
+	if !ok {
+		return args, 0
+	}
+	verbs, ok := parseFormat(format)
+	if !ok || len(verbs) != len(args)-1 {
+		return args, 0
+	}
+	var n int
+	newArgs := append([]ast.Expr{}, args...)
+	for i, verb := range verbs {
+		if verb.Flags != "" || (verb.Verb != 's' && verb.Verb != 'v') {
+			continue
+		}
+		call, ok := newArgs[i+1].(*ast.CallExpr)
+		if !ok || len(call.Args) != 0 {
+			continue
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || fun.Sel.Name != "Error" {
+			continue
+		}
+		t := info.TypeOf(fun.X)
+		if t == nil || !types.Implements(t, errorInterface) {
+			continue
+		}
+		newArgs[i+1] = fun.X
+		n++
+	}
+	if n == 0 {
+		return args, 0
+	}
+	return newArgs, n
+}
@@ -0,0 +1,404 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// xerrorsFormatter represents the methods of a type which implements xerrors.Formatter.
+type xerrorsFormatter struct {
+	typeName    string
+	formatError *ast.FuncDecl // FormatError(p xerrors.Printer) error
+	format      *ast.FuncDecl // Format(f fmt.State, c rune) { xerrors.FormatError(e, f, c) }, may be nil
+	error       *ast.FuncDecl // Error() string, nil if it is in another file
+	hasUnwrap   bool          // true if the type has Unwrap() in the package
+}
+
+// formatErrorBody represents the body of FormatError().
+type formatErrorBody struct {
+	message ast.Expr   // message printed by p.Print() or p.Printf()
+	next    ast.Expr   // the next error returned, may be nil
+	lost    []ast.Stmt // statements printing the detail
+}
+
+// migrateXerrorsFormatters rewrites the types which implement xerrors.Formatter
+// to plain Error() and Unwrap() methods.
+//
+// It removes the boilerplate of Format() which calls xerrors.FormatError(),
+// and rewrites FormatError() to Error() and Unwrap() which keep the message and the next error.
+// The detail printed by FormatError() is lost and reported as a diagnostic.
+// If FormatError() is too complex to rewrite, it is left as it is.
+// If Error() is not the boilerplate of fmt.Sprint() in the same file, it is left as it is,
+// because Error() may depend on Format().
+//
+// This must be called before inspecting the function calls,
+// because the calls in the removed methods should not be rewritten.
+// It returns the number of changes.
+func migrateXerrorsFormatters(pkg *packages.Package, file *ast.File, r *reporter) int {
+	var n int
+	for _, f := range findXerrorsFormatters(pkg, file) {
+		if f.error == nil || !isSprintBoilerplate(pkg, f.error) {
+			reason := errors.New("Error() is not fmt.Sprint() of the receiver")
+			if f.error == nil {
+				reason = errors.New("Error() is defined in another file and may depend on Format()")
+			}
+			r.add(formatterDiagnostic(pkg, f, f.formatError, reason))
+			r.keepImport(xerrorsImportPath)
+			continue
+		}
+		body, err := parseFormatErrorBody(pkg, f.formatError)
+		if err != nil {
+			r.add(formatterDiagnostic(pkg, f, f.formatError, err))
+			r.keepImport(xerrorsImportPath)
+			continue
+		}
+		for _, stmt := range body.lost {
+			r.add(formatterDiagnostic(pkg, f, stmt, errors.New("the detail is lost")))
+		}
+		n += rewriteXerrorsFormatter(pkg, file, f, body)
+	}
+	return n
+}
+
+func formatterDiagnostic(pkg *packages.Package, f xerrorsFormatter, node ast.Node, reason error) Diagnostic {
+	return Diagnostic{
+		Position: astio.Position(pkg, node),
+		Function: fmt.Sprintf("%s.%s.FormatError", pkg.Types.Path(), f.typeName),
+		Reason:   reason.Error(),
+	}
+}
+
+func findXerrorsFormatters(pkg *packages.Package, file *ast.File) []xerrorsFormatter {
+	methods := make(map[string]map[string]*ast.FuncDecl)
+	var typeNames []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+		typeName := receiverTypeName(fn)
+		if typeName == "" {
+			continue
+		}
+		if methods[typeName] == nil {
+			methods[typeName] = make(map[string]*ast.FuncDecl)
+			typeNames = append(typeNames, typeName)
+		}
+		methods[typeName][fn.Name.Name] = fn
+	}
+
+	var formatters []xerrorsFormatter
+	for _, typeName := range typeNames {
+		m := methods[typeName]
+		formatError := m["FormatError"]
+		if formatError == nil || !isXerrorsPrinterParam(pkg, formatError) {
+			continue
+		}
+		f := xerrorsFormatter{
+			typeName:    typeName,
+			formatError: formatError,
+			error:       m["Error"],
+		}
+		if format := m["Format"]; format != nil && isXerrorsFormatBoilerplate(pkg, format) {
+			f.format = format
+		}
+		if obj := pkg.Types.Scope().Lookup(typeName); obj != nil {
+			ms := types.NewMethodSet(types.NewPointer(obj.Type()))
+			f.hasUnwrap = ms.Lookup(pkg.Types, "Unwrap") != nil
+		}
+		formatters = append(formatters, f)
+	}
+	return formatters
+}
+
+func receiverTypeName(fn *ast.FuncDecl) string {
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// isXerrorsPrinterParam returns true if the function has the only parameter of xerrors.Printer.
+func isXerrorsPrinterParam(pkg *packages.Package, fn *ast.FuncDecl) bool {
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	named, ok := pkg.TypesInfo.TypeOf(params[0].Type).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == xerrorsImportPath && named.Obj().Name() == "Printer"
+}
+
+// isXerrorsFormatBoilerplate returns true if the method only calls xerrors.FormatError().
+func isXerrorsFormatBoilerplate(pkg *packages.Package, fn *ast.FuncDecl) bool {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return false
+	}
+	stmt, ok := fn.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	_, ok = packageFunctionCallOf(pkg.TypesInfo, stmt.X, xerrorsImportPath, "FormatError")
+	return ok
+}
+
+// isSprintBoilerplate returns true if the method only returns fmt.Sprint() of the receiver,
+// which depends on Format().
+func isSprintBoilerplate(pkg *packages.Package, fn *ast.FuncDecl) bool {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return false
+	}
+	if call, ok := packageFunctionCallOf(pkg.TypesInfo, ret.Results[0], "fmt", "Sprint"); ok {
+		return len(call.Args) == 1
+	}
+	if call, ok := packageFunctionCallOf(pkg.TypesInfo, ret.Results[0], "fmt", "Sprintf"); ok {
+		format, _ := stringLiteral(call.Args[0])
+		return len(call.Args) == 2 && (format == "%v" || format == "%s")
+	}
+	return false
+}
+
+// parseFormatErrorBody parses the body of FormatError().
+// It supports p.Print(), p.Printf(), p.Detail() and Frame.Format(p), followed by a return statement.
+func parseFormatErrorBody(pkg *packages.Package, fn *ast.FuncDecl) (*formatErrorBody, error) {
+	if fn.Body == nil || len(fn.Body.List) == 0 {
+		return nil, errors.New("FormatError has no statement")
+	}
+	var printer types.Object
+	if names := fn.Type.Params.List[0].Names; len(names) == 1 {
+		printer = pkg.TypesInfo.ObjectOf(names[0])
+	}
+	isPrinterCall := func(expr ast.Expr, names ...string) (*ast.CallExpr, bool) {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || printer == nil {
+			return nil, false
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil, false
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok || pkg.TypesInfo.ObjectOf(x) != printer {
+			return nil, false
+		}
+		for _, name := range names {
+			if sel.Sel.Name == name {
+				return call, true
+			}
+		}
+		return nil, false
+	}
+
+	var body formatErrorBody
+	var messages []ast.Expr
+	stmts := fn.Body.List
+	for i, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.ExprStmt:
+			if call, ok := isPrinterCall(stmt.X, "Print", "Printf"); ok {
+				message, err := printerCallMessage(pkg, call)
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, message)
+				continue
+			}
+			if isFrameFormat(pkg, stmt.X) {
+				body.lost = append(body.lost, stmt)
+				continue
+			}
+		case *ast.IfStmt:
+			if stmt.Init == nil && stmt.Else == nil {
+				if _, ok := isPrinterCall(stmt.Cond, "Detail"); ok {
+					body.lost = append(body.lost, stmt)
+					continue
+				}
+			}
+		case *ast.ReturnStmt:
+			if i == len(stmts)-1 && len(stmt.Results) == 1 {
+				if ident, ok := stmt.Results[0].(*ast.Ident); !ok || ident.Name != "nil" {
+					body.next = stmt.Results[0]
+				}
+				continue
+			}
+		}
+		return nil, fmt.Errorf("FormatError has an unsupported statement at %s", astio.Position(pkg, stmt))
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("FormatError does not print any message")
+	}
+	body.message = messages[0]
+	for _, message := range messages[1:] {
+		body.message = &ast.BinaryExpr{X: body.message, Op: token.ADD, Y: message}
+	}
+	return &body, nil
+}
+
+// printerCallMessage returns an expression of the message printed by p.Print() or p.Printf().
+func printerCallMessage(pkg *packages.Package, call *ast.CallExpr) (ast.Expr, error) {
+	if call.Ellipsis.IsValid() || len(call.Args) == 0 {
+		return nil, errors.New("FormatError has an unsupported call of Printer")
+	}
+	fun := call.Fun.(*ast.SelectorExpr)
+	if fun.Sel.Name == "Print" {
+		if len(call.Args) == 1 {
+			if basic, ok := pkg.TypesInfo.TypeOf(call.Args[0]).Underlying().(*types.Basic); ok && basic.Kind() == types.String {
+				return call.Args[0], nil
+			}
+		}
+		return newPackageFunctionCall("fmt", "Sprint", call.Args...), nil
+	}
+	if len(call.Args) == 1 {
+		if format, ok := stringLiteral(call.Args[0]); ok {
+			if verbs, ok := parseFormat(format); ok && len(verbs) == 0 {
+				return call.Args[0], nil
+			}
+		}
+	}
+	return newPackageFunctionCall("fmt", "Sprintf", call.Args...), nil
+}
+
+// isFrameFormat returns true if expr is a call of xerrors.Frame.Format().
+func isFrameFormat(pkg *packages.Package, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Format" {
+		return false
+	}
+	named, ok := pkg.TypesInfo.TypeOf(sel.X).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == xerrorsImportPath && named.Obj().Name() == "Frame"
+}
+
+func newPackageFunctionCall(pkgName, funName string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: ast.NewIdent(funName)},
+		Args: args,
+	}
+}
+
+// rewriteXerrorsFormatter rewrites the methods of the formatter.
+// Error() must be the boilerplate of fmt.Sprint(), which depends on Format().
+// FormatError() is rewritten to Unwrap() in place if the type does not have Unwrap().
+func rewriteXerrorsFormatter(pkg *packages.Package, file *ast.File, f xerrorsFormatter, body *formatErrorBody) int {
+	var n int
+	f.error.Recv.List[0].Names = copyIdents(f.formatError.Recv.List[0].Names)
+	// keep the closing brace next to the new statements
+	f.error.Body.Rbrace = f.error.Body.List[len(f.error.Body.List)-1].End()
+	f.error.Body.List = newErrorMethodBody(pkg.Fset, body)
+	log.Printf("%s: rewrote %s.Error() with the message of FormatError()", astio.Position(pkg, f.error), f.typeName)
+	n++
+	if f.format != nil {
+		log.Printf("%s: removed %s.Format()", astio.Position(pkg, f.format), f.typeName)
+		removeDecl(file, f.format)
+		n++
+	}
+	if body.next != nil && !f.hasUnwrap {
+		rewriteMethodDecl(f.formatError, "Unwrap", "error", []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{body.next}}})
+		setMethodDoc(file, f.formatError, "// Unwrap returns the next error in the error chain.")
+		log.Printf("%s: rewrote %s.FormatError() to Unwrap()", astio.Position(pkg, f.formatError), f.typeName)
+		n++
+	} else {
+		log.Printf("%s: removed %s.FormatError()", astio.Position(pkg, f.formatError), f.typeName)
+		removeDecl(file, f.formatError)
+		n++
+	}
+	if usesPackageName(file, "fmt") && astutil.AddImport(pkg.Fset, file, "fmt") {
+		log.Printf("%s: + import %s", astio.Filename(pkg, file), "fmt")
+		n++
+	}
+	return n
+}
+
+// newErrorMethodBody returns the statements of Error() which returns the message and the next error.
+// The expressions are copied without the positions,
+// because they are moved from FormatError() and the positions would break the comments.
+func newErrorMethodBody(fset *token.FileSet, body *formatErrorBody) []ast.Stmt {
+	message := func() ast.Expr { return copyExpr(fset, body.message) }
+	if body.next == nil {
+		return []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{message()}}}
+	}
+	next := func() ast.Expr { return copyExpr(fset, body.next) }
+	return []ast.Stmt{
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: next(), Op: token.NEQ, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.BinaryExpr{
+						X:  &ast.BinaryExpr{X: message(), Op: token.ADD, Y: newStringLiteral(": ")},
+						Op: token.ADD,
+						Y:  &ast.CallExpr{Fun: &ast.SelectorExpr{X: next(), Sel: ast.NewIdent("Error")}},
+					},
+				}},
+			}},
+		},
+		&ast.ReturnStmt{Results: []ast.Expr{message()}},
+	}
+}
+
+// rewriteMethodDecl replaces the signature and body of the method, keeping the receiver.
+func rewriteMethodDecl(fn *ast.FuncDecl, name, result string, stmts []ast.Stmt) {
+	fn.Name.Name = name
+	fn.Type.Params.List = nil
+	fn.Type.Results = &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent(result)}}}
+	fn.Body.List = stmts
+}
+
+// setMethodDoc removes the comments in the method and replaces the doc comment.
+func setMethodDoc(file *ast.File, fn *ast.FuncDecl, text string) {
+	removeComments(file, fn.Body.Lbrace, fn.Body.Rbrace)
+	if fn.Doc == nil {
+		return
+	}
+	fn.Doc.List = fn.Doc.List[:1]
+	fn.Doc.List[0].Text = text
+}
+
+func removeDecl(file *ast.File, fn *ast.FuncDecl) {
+	start := fn.Pos()
+	if fn.Doc != nil {
+		start = fn.Doc.Pos()
+	}
+	removeComments(file, start, fn.End())
+	for i, decl := range file.Decls {
+		if decl == fn {
+			file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+			return
+		}
+	}
+}
+
+// removeComments removes the comments in the range.
+func removeComments(file *ast.File, start, end token.Pos) {
+	var comments []*ast.CommentGroup
+	for _, c := range file.Comments {
+		if c.Pos() >= start && c.End() <= end {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	file.Comments = comments
+}
@@ -0,0 +1,146 @@
+package rewrite
+
+import (
+	"go/ast"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// callerFrameFuncName is the name of the function which replaces xerrors.Caller().
+const callerFrameFuncName = "callerFrame"
+
+const callerFrameFuncDecl = `func callerFrame(skip int) runtime.Frame {
+	pc := make([]uintptr, 1)
+	runtime.Callers(skip+2, pc)
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return frame
+}
+`
+
+// migrateXerrorsFrames rewrites xerrors.Frame to runtime.Frame.
+//
+// It rewrites the following syntax:
+//
+//	xerrors.Frame        -> runtime.Frame
+//	xerrors.Caller(skip) -> callerFrame(skip)
+//
+// The function callerFrame() is added to the package if it does not exist.
+// If the file still uses xerrors, e.g., FormatError() is left, it does nothing
+// and xerrors.Caller() is reported by the visitor.
+//
+// This must be called after migrateXerrorsFormatters,
+// because Frame.Format() in FormatError() is removed by it.
+// It returns the number of changes.
+func migrateXerrorsFrames(pkg *packages.Package, file *ast.File, r *reporter) int {
+	if r.remainingImports[xerrorsImportPath] || !usesXerrorsFrame(pkg, file) {
+		return 0
+	}
+	if obj := pkg.Types.Scope().Lookup(callerFrameFuncName); obj != nil {
+		log.Printf("%s: NOTE: could not rewrite xerrors.Frame: %s is already declared", astio.Filename(pkg, file), callerFrameFuncName)
+		r.keepImport(xerrorsImportPath)
+		return 0
+	}
+	if !checkFrameMethodCalls(pkg, file, r) {
+		r.keepImport(xerrorsImportPath)
+		return 0
+	}
+	var n int
+	var needCallerFrame bool
+	astutil.Apply(file, func(c *astutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.SelectorExpr:
+			if isXerrorsObject(pkg, node.Sel, "Frame") {
+				log.Printf("%s: xerrors.Frame -> runtime.Frame", astio.Position(pkg, node))
+				c.Replace(&ast.SelectorExpr{
+					X:   &ast.Ident{NamePos: node.X.Pos(), Name: "runtime"},
+					Sel: node.Sel,
+				})
+				n++
+				return false
+			}
+		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok && isXerrorsObject(pkg, sel.Sel, "Caller") {
+				log.Printf("%s: xerrors.Caller() -> %s()", astio.Position(pkg, node), callerFrameFuncName)
+				node.Fun = &ast.Ident{NamePos: sel.Pos(), Name: callerFrameFuncName}
+				needCallerFrame = true
+				n++
+			}
+		}
+		return true
+	}, nil)
+	if n == 0 {
+		return 0
+	}
+	if needCallerFrame && !hasFuncDecl(pkg, callerFrameFuncName) {
+		file.Decls = append(file.Decls, newFuncDecl(pkg.Fset, callerFrameFuncDecl, file.End()))
+		log.Printf("%s: + func %s()", astio.Filename(pkg, file), callerFrameFuncName)
+		n++
+	}
+	if astutil.AddImport(pkg.Fset, file, "runtime") {
+		log.Printf("%s: + import %s", astio.Filename(pkg, file), "runtime")
+		n++
+	}
+	return n
+}
+
+// usesXerrorsFrame returns true if the file uses xerrors.Frame or xerrors.Caller().
+func usesXerrorsFrame(pkg *packages.Package, file *ast.File) bool {
+	var found bool
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Ident:
+			if isXerrorsObject(pkg, node, "Frame") || isXerrorsObject(pkg, node, "Caller") {
+				found = true
+			}
+		case *ast.CallExpr:
+			if isFrameFormat(pkg, node) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// checkFrameMethodCalls returns true if all the method calls of xerrors.Frame can be rewritten.
+// Otherwise it reports the method calls.
+func checkFrameMethodCalls(pkg *packages.Package, file *ast.File, r *reporter) bool {
+	ok := true
+	ast.Inspect(file, func(node ast.Node) bool {
+		if call, isCall := node.(*ast.CallExpr); isCall && isFrameFormat(pkg, call) {
+			r.add(Diagnostic{
+				Position: astio.Position(pkg, call),
+				Function: xerrorsImportPath + ".Frame.Format",
+				Reason:   "xerrors.Printer is not available in the target",
+			})
+			ok = false
+		}
+		return true
+	})
+	return ok
+}
+
+// isXerrorsObject returns true if the identifier refers to the object of xerrors.
+func isXerrorsObject(pkg *packages.Package, ident *ast.Ident, name string) bool {
+	obj := pkg.TypesInfo.Uses[ident]
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	return obj.Pkg().Path() == xerrorsImportPath && obj.Name() == name
+}
+
+// hasFuncDecl returns true if any file of the package declares the function.
+// It may have been added by the previous file.
+func hasFuncDecl(pkg *packages.Package, name string) bool {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
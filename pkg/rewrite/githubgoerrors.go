@@ -0,0 +1,146 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"golang.org/x/tools/go/packages"
+)
+
+// renameGitHubGoErrorsFunction renames the function call of github.com/go-errors/errors
+// to the equivalent function of github.com/pkg/errors.
+// The caller can rewrite it as a function call of github.com/pkg/errors.
+//
+//	New("MESSAGE")                  -> New("MESSAGE")
+//	New(err)                        -> WithStack(err)
+//	Wrap(err, 0)                    -> WithStack(err)
+//	WrapPrefix(err, "MESSAGE", 0)   -> Wrap(err, "MESSAGE")
+//
+// It returns an error if the skip argument is not zero,
+// because the target does not support skipping the stack frames.
+func renameGitHubGoErrorsFunction(call astio.PackageFunctionCall) error {
+	args := call.Args()
+	switch call.FunctionName() {
+	case "Errorf", "Unwrap", "As", "Is":
+		return nil
+
+	case "New":
+		if len(args) != 1 {
+			return fmt.Errorf("errors.New expects 1 argument but has %d arguments", len(args))
+		}
+		return renameGitHubGoErrorsNew(call, args[0])
+
+	case "Wrap":
+		if len(args) != 2 {
+			return fmt.Errorf("errors.Wrap expects 2 arguments but has %d arguments", len(args))
+		}
+		if !isZeroConstant(call.TypesInfo, args[1]) {
+			return errors.New("skip argument is not supported")
+		}
+		call.SetArgs(args[:1])
+		return renameGitHubGoErrorsNew(call, args[0])
+
+	case "WrapPrefix":
+		if len(args) != 3 {
+			return fmt.Errorf("errors.WrapPrefix expects 3 arguments but has %d arguments", len(args))
+		}
+		if !isZeroConstant(call.TypesInfo, args[2]) {
+			return errors.New("skip argument is not supported")
+		}
+		if !isErrorType(call.TypesInfo.TypeOf(args[0])) {
+			return errors.New("1st argument of errors.WrapPrefix must be an error")
+		}
+		call.SetArgs(args[:2])
+		call.TargetFun.Sel.Name = "Wrap"
+		return nil
+	}
+
+	return errUnsupportedFunction
+}
+
+// renameGitHubGoErrorsNew renames the function call to New or WithStack,
+// depending on whether the argument is a string or an error.
+func renameGitHubGoErrorsNew(call astio.PackageFunctionCall, arg ast.Expr) error {
+	t := call.TypesInfo.TypeOf(arg)
+	if b, ok := t.(*types.Basic); ok && (b.Kind() == types.String || b.Kind() == types.UntypedString) {
+		call.TargetFun.Sel.Name = "New"
+		return nil
+	}
+	if isErrorType(t) {
+		call.TargetFun.Sel.Name = "WithStack"
+		return nil
+	}
+	return fmt.Errorf("argument of type %s is not supported", t)
+}
+
+// isZeroConstant returns true if the expression is a constant of zero.
+func isZeroConstant(info *types.Info, expr ast.Expr) bool {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return false
+	}
+	return constant.Compare(tv.Value, token.EQL, constant.MakeInt64(0))
+}
+
+// isErrorType returns true if the type implements error.
+func isErrorType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	return types.Implements(t, types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+}
+
+// githubGoErrorsStackMethods are the methods of github.com/go-errors/errors.Error
+// which are not available in the target.
+var githubGoErrorsStackMethods = map[string]bool{
+	"ErrorStack":  true,
+	"Stack":       true,
+	"StackFrames": true,
+	"Callers":     true,
+	"TypeName":    true,
+}
+
+// reportSourceTypes reports the references to the types of github.com/go-errors/errors
+// and github.com/rotisserie/eris, and the method calls of the stack trace of them.
+// The references and the method calls are left as they are with a TODO comment.
+// The packages which are not selected as the source are not reported.
+func reportSourceTypes(pkg *packages.Package, file *ast.File, filter *callFilter, r *reporter) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); ok {
+				if !filter.selected(pkgName.Imported().Path()) {
+					return false
+				}
+				return reportSourceType(pkg, sel, pkgName.Imported().Path(), r)
+			}
+		}
+		s, ok := pkg.TypesInfo.Selections[sel]
+		if !ok || s.Kind() != types.MethodVal || !githubGoErrorsStackMethods[sel.Sel.Name] || !filter.selected(githubGoErrorsImportPath) {
+			return true
+		}
+		if fn, ok := s.Obj().(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == githubGoErrorsImportPath {
+			r.reportMethod(astio.Position(pkg, sel.Sel), sel, githubGoErrorsImportPath+".Error."+sel.Sel.Name,
+				errors.New("the stack trace is not available in the target"))
+		}
+		return true
+	})
+}
+
+func reportSourceType(pkg *packages.Package, sel *ast.SelectorExpr, path string, r *reporter) bool {
+	if path != githubGoErrorsImportPath && path != erisImportPath {
+		return false
+	}
+	if _, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.TypeName); ok {
+		r.reportRef(astio.Position(pkg, sel), sel, path, errors.New("the type is not available in the target"))
+	}
+	return false
+}
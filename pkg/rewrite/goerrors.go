@@ -1,6 +1,7 @@
 package rewrite
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
@@ -12,23 +13,67 @@ import (
 	"golang.org/x/tools/go/packages"
 )
 
-type toGoErrors struct{}
+type toGoErrors struct {
+	sentinels  *sentinelPackage
+	customs    customPackages
+	filter     *callFilter
+	joinErrors bool // errors.Join is available in Go 1.20 or later
+}
 
-func (t *toGoErrors) Transform(pkg *packages.Package, file *ast.File) (int, error) {
+func (t *toGoErrors) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
 	var v toGoErrorsVisitor
-	if err := astio.Inspect(pkg, file, &v); err != nil {
-		return 0, fmt.Errorf("could not inspect the file: %w", err)
+	v.sentinels = t.sentinels
+	v.errorfWraps = true
+	v.customs = t.customs
+	v.filter = t.filter
+	v.joinErrors = t.joinErrors
+	v.canCombine = pkg.Types.Scope().Lookup(combineErrorsFuncName) == nil
+	v.canUnwrap = pkg.Types.Scope().Lookup(unwrapErrorsFuncName) == nil
+	// compute the ignored calls once, before the passes replace any node of the file
+	ignored := astio.IgnoredCalls(pkg, file)
+	var m int
+	if t.filter.selected(xerrorsImportPath) {
+		m += migrateXerrorsFormatters(pkg, file, &v.reporter)
+		m += migrateXerrorsFrames(pkg, file, &v.reporter)
+	}
+	m += addDelegateMethods(pkg, file, "Cause", "Unwrap", &v.reporter)
+	if t.filter.selected(jujuErrorsImportPath) {
+		m += v.replaceJujuConstErrors(pkg, file, ignored, t.filter, &v.reporter)
+	}
+	if t.filter.selected(erisImportPath) {
+		m += replaceErisToString(pkg, file, ignored, t.filter)
+	}
+	reportSourceTypes(pkg, file, t.filter, &v.reporter)
+	if t.joinErrors {
+		n, keptCalls := migrateMultiErrors(pkg, file, ignored, t.filter, &v.reporter)
+		m += n
+		v.keptMultierrorCalls = keptCalls
+		if t.filter.selected(utilerrorsImportPath) {
+			m += migrateAggregates(pkg, file, ignored, t.filter, &v.reporter)
+		}
+	}
+	t.filter.keepUnselectedImports(file, t.customs, "errors", &v.reporter)
+	if err := astio.InspectWithIgnoredCalls(pkg, file, ignored, &v); err != nil {
+		return 0, nil, fmt.Errorf("could not inspect the file: %w", err)
+	}
+	if v.needCombineErrors {
+		m += addFuncDecl(pkg, file, combineErrorsFuncName, combineErrorsFuncDecl)
 	}
-	if v.needImportFmt == 0 && v.needImportErrors == 0 {
-		return 0, nil
+	r := normalizeErrorf(pkg, file, ignored, t.filter, "fmt", "fmt", "errors")
+	c := rewriteChainWalks(pkg, file, t.filter, GoErrors, "errors")
+	if v.needImportFmt == 0 && v.needImportErrors == 0 && r.changes == 0 && m == 0 && c == 0 && len(v.remainingCalls) == 0 && len(v.remainingRefs) == 0 && len(v.remainingMethods) == 0 {
+		return 0, v.diagnostics, nil
 	}
-	n := t.replaceImports(pkg, file, v.needImportFmt, v.needImportErrors)
-	return v.needImportFmt + v.needImportErrors + n, nil
+	n := v.addSentinelImport(pkg, file)
+	n += t.replaceImports(pkg, file, v.needImportFmt, v.needImportErrors+r.newCalls+c, v.remainingImportPaths())
+	n += keepRemainingCalls(pkg, file, &v.reporter)
+	return v.needImportFmt + v.needImportErrors + r.changes + m + c + n, v.diagnostics, nil
 }
 
-func (*toGoErrors) replaceImports(pkg *packages.Package, file *ast.File, needImportFmt, needImportErrors int) int {
+func (t *toGoErrors) replaceImports(pkg *packages.Package, file *ast.File, needImportFmt, needImportErrors int, keepImports map[string]bool) int {
 	var n int
-	if needImportFmt > 0 {
+	// Errorf may have been normalized to New
+	if needImportFmt > 0 && usesPackageName(file, "fmt") {
 		if astutil.AddImport(pkg.Fset, file, "fmt") {
 			n++
 			log.Printf("%s: + import %s", astio.Filename(pkg, file), "fmt")
@@ -40,14 +85,49 @@ func (*toGoErrors) replaceImports(pkg *packages.Package, file *ast.File, needImp
 			log.Printf("%s: + import %s", astio.Filename(pkg, file), "errors")
 		}
 	}
-	if astutil.DeleteImport(pkg.Fset, file, xerrorsImportPath) {
+	if !keepImports[xerrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, xerrorsImportPath) {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), xerrorsImportPath)
 	}
-	if astutil.DeleteImport(pkg.Fset, file, pkgErrorsImportPath) {
+	if !keepImports[pkgErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, pkgErrorsImportPath) {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), pkgErrorsImportPath)
 	}
+	if !keepImports[cockroachErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, cockroachErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), cockroachErrorsImportPath)
+	}
+	if !keepImports[jujuErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, jujuErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), jujuErrorsImportPath)
+	}
+	if !keepImports[githubGoErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, githubGoErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), githubGoErrorsImportPath)
+	}
+	if !keepImports[erisImportPath] && astutil.DeleteImport(pkg.Fset, file, erisImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), erisImportPath)
+	}
+	n += t.customs.deleteImports(pkg, file, keepImports)
+	if !keepImports[multierrImportPath] && astutil.DeleteImport(pkg.Fset, file, multierrImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), multierrImportPath)
+	}
+	if !keepImports[multierrorImportPath] && astutil.DeleteImport(pkg.Fset, file, multierrorImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), multierrorImportPath)
+	}
+	if !keepImports[utilerrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, utilerrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), utilerrorsImportPath)
+	}
+	if !astutil.UsesImport(file, "fmt") {
+		if astutil.DeleteImport(pkg.Fset, file, "fmt") {
+			n++
+			log.Printf("%s: - import %s", astio.Filename(pkg, file), "fmt")
+		}
+	}
 	if n > 0 {
 		ast.SortImports(pkg.Fset, file)
 	}
@@ -55,16 +135,52 @@ func (*toGoErrors) replaceImports(pkg *packages.Package, file *ast.File, needImp
 }
 
 type toGoErrorsVisitor struct {
+	reporter
+	jujuErrors
+	customs          customPackages
+	filter           *callFilter
 	needImportFmt    int
 	needImportErrors int
+	joinErrors       bool
+
+	canCombine        bool // combineErrors() is not declared in the package
+	canUnwrap         bool // unwrapErrors() is not declared in the package
+	needCombineErrors bool
+
+	keptMultierrorCalls map[*ast.CallExpr]bool // multierror.Append() of the declarations left as they are
 }
 
 func (v *toGoErrorsVisitor) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	if skipCall(v.filter, call, &v.reporter) {
+		return nil
+	}
+	var err error
 	switch call.PackagePath() {
 	case pkgErrorsImportPath:
-		return v.pkgErrorsFunctionCall(call)
+		err = v.pkgErrorsFunctionCall(call)
 	case xerrorsImportPath:
-		return v.xerrorsFunctionCall(call)
+		err = v.xerrorsFunctionCall(call)
+	case cockroachErrorsImportPath:
+		err = v.cockroachErrorsFunctionCall(call)
+	case jujuErrorsImportPath:
+		err = v.jujuErrorsFunctionCall(call, v.pkgErrorsFunctionCall)
+	case githubGoErrorsImportPath:
+		err = replaceRenamedFunctionCall(call, renameGitHubGoErrorsFunction, v.pkgErrorsFunctionCall)
+	case erisImportPath:
+		err = replaceRenamedFunctionCall(call, renameErisFunction, v.pkgErrorsFunctionCall)
+	case multierrImportPath:
+		err = v.multierrFunctionCall(call)
+	case multierrorImportPath:
+		err = v.multierrorFunctionCall(call)
+	case utilerrorsImportPath:
+		err = v.utilerrorsFunctionCall(call)
+	default:
+		if v.customs.find(call.PackagePath()) != nil {
+			err = replaceRenamedFunctionCall(call, v.customs.renameFunction, v.pkgErrorsFunctionCall)
+		}
+	}
+	if err != nil {
+		v.report(call, err)
 	}
 	return nil
 }
@@ -73,13 +189,16 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 	switch call.FunctionName() {
 	case "Wrapf":
 		args := call.Args()
+		if len(args) < 2 {
+			return fmt.Errorf("errors.Wrapf expects 2 or more arguments but has %d arguments", len(args))
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
 		// append %w to the format arg
 		b, ok := args[1].(*ast.BasicLit)
-		if !ok {
-			return fmt.Errorf("%s: 2nd argument of Wrapf must be a literal but was %T", call.Position, args[1])
-		}
-		if b.Kind != token.STRING {
-			return fmt.Errorf("%s: 2nd argument of Wrapf must be a string but was %s", call.Position, b.Kind)
+		if !ok || b.Kind != token.STRING {
+			return errors.New("2nd argument of Wrapf must be a string literal")
 		}
 		b.Value = strings.TrimSuffix(b.Value, `"`) + `: %w"`
 
@@ -88,18 +207,28 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 		newArgs = append(newArgs, args[1])
 		newArgs = append(newArgs, args[2:]...)
 		newArgs = append(newArgs, args[0])
-		call.SetArgs(newArgs)
+		call.SetArgs(inlineSprintf(call.TypesInfo, newArgs))
 
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
 
 	case "Errorf":
+		call.SetArgs(inlineSprintf(call.TypesInfo, call.Args()))
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
 
-	case "New", "Unwrap", "As", "Is":
+	case "New":
+		if replaceNewSprintfWithErrorf(call, "fmt") {
+			v.needImportFmt++
+			return nil
+		}
+		replacePackageFunctionCall(call, "errors", "")
+		v.needImportErrors++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "errors", "")
 		v.needImportErrors++
 		return nil
@@ -112,13 +241,13 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 	case "Wrap":
 		args := call.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("%s: errors.Wrap expects 2 arguments but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.Wrap expects 2 arguments but has %d arguments", len(args))
 		}
-		call.SetArgs([]ast.Expr{
-			&ast.BasicLit{Value: `"%s: %w"`},
+		call.SetArgs(inlineSprintf(call.TypesInfo, []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%s: %w"`},
 			args[1],
 			args[0],
-		})
+		}))
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
@@ -126,7 +255,7 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 	case "WithStack":
 		args := call.Args()
 		if len(args) != 1 {
-			return fmt.Errorf("%s: errors.WithStack expects 1 argument but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.WithStack expects 1 argument but has %d arguments", len(args))
 		}
 		call.SetArgs([]ast.Expr{
 			&ast.BasicLit{Value: `"%w"`},
@@ -139,26 +268,29 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 	case "WithMessage":
 		args := call.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("%s: errors.WithMessage expects 2 arguments but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.WithMessage expects 2 arguments but has %d arguments", len(args))
 		}
-		call.SetArgs([]ast.Expr{
-			&ast.BasicLit{Value: `"%s: %s"`},
+		call.SetArgs(inlineSprintf(call.TypesInfo, []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%s: %s"`},
 			args[1],
 			args[0],
-		})
+		}))
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
 
 	case "WithMessagef":
 		args := call.Args()
+		if len(args) < 2 {
+			return fmt.Errorf("errors.WithMessagef expects 2 or more arguments but has %d arguments", len(args))
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
 		// append %s to the format arg
 		b, ok := args[1].(*ast.BasicLit)
-		if !ok {
-			return fmt.Errorf("%s: 2nd argument of WithMessagef must be a literal but %T", call.Position, args[1])
-		}
-		if b.Kind != token.STRING {
-			return fmt.Errorf("%s: 2nd argument of WithMessagef must be a string but %s", call.Position, b.Kind)
+		if !ok || b.Kind != token.STRING {
+			return errors.New("2nd argument of WithMessagef must be a string literal")
 		}
 		b.Value = strings.TrimSuffix(b.Value, `"`) + `: %s"`
 
@@ -167,34 +299,139 @@ func (v *toGoErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall
 		newArgs = append(newArgs, args[1])
 		newArgs = append(newArgs, args[2:]...)
 		newArgs = append(newArgs, args[0])
-		call.SetArgs(newArgs)
+		call.SetArgs(inlineSprintf(call.TypesInfo, newArgs))
 
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "errors"
-	v.needImportErrors++
-	return nil
+	return errUnsupportedFunction
 }
 
 func (v *toGoErrorsVisitor) xerrorsFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
 	case "Errorf":
+		call.SetArgs(inlineSprintf(call.TypesInfo, call.Args()))
 		replacePackageFunctionCall(call, "fmt", "Errorf")
 		v.needImportFmt++
 		return nil
 
-	case "New", "Unwrap", "As", "Is":
+	case "New":
+		if replaceNewSprintfWithErrorf(call, "fmt") {
+			v.needImportFmt++
+			return nil
+		}
+		replacePackageFunctionCall(call, "errors", "")
+		v.needImportErrors++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "errors", "")
 		v.needImportErrors++
 		return nil
+
+	case "Caller":
+		return errors.New("xerrors.Frame could not be rewritten in the file")
+
+	case "Opaque":
+		args := call.Args()
+		if len(args) != 1 {
+			return fmt.Errorf("xerrors.Opaque expects 1 argument but has %d arguments", len(args))
+		}
+		// %v does not wrap the error
+		call.SetArgs([]ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%v"`},
+			args[0],
+		})
+		replacePackageFunctionCall(call, "fmt", "Errorf")
+		v.needImportFmt++
+		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "errors"
-	v.needImportErrors++
-	return nil
+	return errUnsupportedFunction
+}
+
+func (v *toGoErrorsVisitor) cockroachErrorsFunctionCall(call astio.PackageFunctionCall) error {
+	renameCockroachErrorsFunction(call)
+	if call.FunctionName() == "Opaque" {
+		return v.xerrorsFunctionCall(call)
+	}
+	return v.pkgErrorsFunctionCall(call)
+}
+
+var errJoinUnavailable = errors.New("errors.Join is available in Go 1.20 or later")
+
+func (v *toGoErrorsVisitor) multierrFunctionCall(call astio.PackageFunctionCall) error {
+	if !v.joinErrors {
+		return errJoinUnavailable
+	}
+	switch call.FunctionName() {
+	case "Append", "Combine":
+		// errors.Join() wraps even a single error, but multierr returns it as it is
+		if !v.canCombine {
+			return fmt.Errorf("%s() is already declared", combineErrorsFuncName)
+		}
+		log.Printf("%s: %s.%s() -> %s()", call.Position, call.TargetPkg.Name, call.FunctionName(), combineErrorsFuncName)
+		call.Call.Fun = &ast.Ident{NamePos: call.Call.Fun.Pos(), Name: combineErrorsFuncName}
+		v.needCombineErrors = true
+		v.needImportErrors++
+		return nil
+
+	case "Errors":
+		// migrateMultiErrors has rewritten the call if possible.
+		// The call which is ignored or not selected is skipped before here.
+		if !v.canUnwrap {
+			return fmt.Errorf("%s() is already declared", unwrapErrorsFuncName)
+		}
+		return fmt.Errorf("multierr.Errors expects 1 argument but has %d arguments", len(call.Args()))
+	}
+
+	return errUnsupportedFunction
+}
+
+func (v *toGoErrorsVisitor) multierrorFunctionCall(call astio.PackageFunctionCall) error {
+	if !v.joinErrors {
+		return errJoinUnavailable
+	}
+	switch call.FunctionName() {
+	case "Append":
+		if v.keptMultierrorCalls[call.Call] {
+			v.skip(call)
+			return nil
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
+		replacePackageFunctionCall(call, "errors", "Join")
+		v.needImportErrors++
+		return nil
+	}
+
+	return errUnsupportedFunction
+}
+
+func (v *toGoErrorsVisitor) utilerrorsFunctionCall(call astio.PackageFunctionCall) error {
+	if !v.joinErrors {
+		return errJoinUnavailable
+	}
+	switch call.FunctionName() {
+	case "NewAggregate":
+		args := call.Args()
+		if len(args) != 1 {
+			return fmt.Errorf("utilerrors.NewAggregate expects 1 argument but has %d arguments", len(args))
+		}
+		// NewAggregate(errs) -> Join(errs...)
+		call.Call.Ellipsis = args[0].End()
+		replacePackageFunctionCall(call, "errors", "Join")
+		v.needImportErrors++
+		return nil
+
+	case "Flatten":
+		return fmt.Errorf("%s() is already declared", unwrapErrorsFuncName)
+	case "Reduce":
+		return fmt.Errorf("%s() is already declared", reduceErrorsFuncName)
+	}
+
+	return errUnsupportedFunction
 }
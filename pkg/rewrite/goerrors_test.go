@@ -20,4 +20,249 @@ func TestToGoErrors_Transform(t *testing.T) {
 			"testdata/pkgerrors/common.go",
 			"testdata/goerrors/common.go")
 	})
+	t.Run("fmt.Sprintf from xerrors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/xerrors/sprintf.go",
+			"testdata/goerrors/sprintf_collapsed.go")
+	})
+	t.Run("fmt.Sprintf from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/sprintf.go",
+			"testdata/goerrors/sprintf_collapsed.go")
+	})
+	t.Run("Errorf from xerrors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/xerrors/errorf.go",
+			"testdata/goerrors/errorf_normalized.go")
+	})
+	t.Run("Errorf from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/errorf.go",
+			"testdata/goerrors/errorf_normalized.go")
+	})
+	t.Run("Errorf of go-errors is left as it is", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/errorf.go",
+			"testdata/goerrors/errorf.go")
+	})
+	t.Run("unsupported syntax from pkg-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/unsupported.go",
+			"testdata/goerrors/unsupported_from_pkgerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("unsupported syntax from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/unsupported.go",
+			"testdata/goerrors/unsupported_from_xerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("formatter from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/formatter.go",
+			"testdata/goerrors/formatter_from_xerrors.go")
+		// the lost detail, MessageError and the call of xerrors.FormatError() in it
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("frame from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/frame.go",
+			"testdata/goerrors/frame_from_xerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("causer from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/causer.go",
+			"testdata/goerrors/causer_from_pkgerrors.go")
+	})
+	t.Run("chain walk from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/chain.go",
+			"testdata/goerrors/chain_from_pkgerrors.go")
+	})
+	t.Run("skipped chain walk from pkg-errors", func(t *testing.T) {
+		tr := toGoErrors{filter: &callFilter{skip: map[string]bool{pkgErrorsImportPath + ".Cause": true}}}
+		transform(t, &tr,
+			"testdata/pkgerrors/chain.go",
+			"testdata/goerrors/chain_skipped_from_pkgerrors.go")
+	})
+	t.Run("common syntax from cockroach-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/cockroacherrors/common.go",
+			"testdata/goerrors/common.go")
+	})
+	t.Run("specific syntax from cockroach-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/cockroacherrors/specific.go",
+			"testdata/goerrors/specific_from_cockroacherrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from juju-errors", func(t *testing.T) {
+		tr := toGoErrors{sentinels: &sentinelPackage{Path: "github.com/int128/errto/errkind"}}
+		diagnostics := transform(t, &tr,
+			"testdata/jujuerrors/specific.go",
+			"testdata/goerrors/specific_from_jujuerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from github go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/githubgoerrors/specific.go",
+			"testdata/goerrors/specific_from_githubgoerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from eris", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/eris/specific.go",
+			"testdata/goerrors/specific_from_eris.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("selected source", func(t *testing.T) {
+		tr := toGoErrors{filter: &callFilter{sources: map[string]bool{xerrorsImportPath: true}}}
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/mixed.go",
+			"testdata/goerrors/mixed_from_xerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("skipped function", func(t *testing.T) {
+		tr := toGoErrors{filter: &callFilter{skip: map[string]bool{pkgErrorsImportPath + ".WithStack": true}}}
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/skip.go",
+			"testdata/goerrors/skip_from_pkgerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("ignore directive", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/ignore.go",
+			"testdata/goerrors/ignore_from_pkgerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("ignore directive from juju-errors", func(t *testing.T) {
+		tr := toGoErrors{sentinels: &sentinelPackage{Path: "github.com/int128/errto/errkind"}}
+		diagnostics := transform(t, &tr,
+			"testdata/jujuerrors/ignore.go",
+			"testdata/goerrors/ignore_from_jujuerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("ignore directive from eris", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/eris/ignore.go",
+			"testdata/goerrors/ignore_from_eris.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("multierr", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true}
+		diagnostics := transform(t, &tr,
+			"testdata/multierr/multierr.go",
+			"testdata/goerrors/join_from_multierr.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("multierr in a file without comments", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true}
+		diagnostics := transform(t, &tr,
+			"testdata/multierr/nocomment.go",
+			"testdata/goerrors/join_nocomment_from_multierr.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("multierr with unwrapErrors declared", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true}
+		diagnostics := transform(t, &tr,
+			"testdata/multierr/declared.go",
+			"testdata/goerrors/join_declared_from_multierr.go")
+		if len(diagnostics) != 1 {
+			t.Fatalf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+		if want := "unwrapErrors() is already declared"; diagnostics[0].Reason != want {
+			t.Errorf("Reason wants %q but was %q", want, diagnostics[0].Reason)
+		}
+	})
+	t.Run("multierr before Go 1.20", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/multierr/multierr.go",
+			"testdata/goerrors/join_unavailable_from_multierr.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("go-multierror", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true}
+		diagnostics := transform(t, &tr,
+			"testdata/multierror/multierror.go",
+			"testdata/goerrors/join_from_multierror.go")
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("multierr with skipped function", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true, filter: &callFilter{skip: map[string]bool{multierrImportPath + ".Errors": true}}}
+		diagnostics := transform(t, &tr,
+			"testdata/multierr/multierr.go",
+			"testdata/goerrors/join_skipped_from_multierr.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("go-multierror with skipped methods", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true, filter: &callFilter{skip: map[string]bool{
+			multierrorImportPath + ".Error.ErrorOrNil":    true,
+			multierrorImportPath + ".Error.WrappedErrors": true,
+			multierrorImportPath + ".Error.Errors":        true,
+		}}}
+		diagnostics := transform(t, &tr,
+			"testdata/multierror/multierror.go",
+			"testdata/goerrors/join_skipped_from_multierror.go")
+		if len(diagnostics) != 5 {
+			t.Errorf("len(diagnostics) wants 5 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("k8s utilerrors", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true}
+		diagnostics := transform(t, &tr,
+			"testdata/utilerrors/utilerrors.go",
+			"testdata/goerrors/join_from_utilerrors.go")
+		if len(diagnostics) != 2 {
+			t.Errorf("len(diagnostics) wants 2 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("k8s utilerrors with skipped functions", func(t *testing.T) {
+		tr := toGoErrors{joinErrors: true, filter: &callFilter{skip: map[string]bool{
+			utilerrorsImportPath + ".Reduce":           true,
+			utilerrorsImportPath + ".Aggregate.Errors": true,
+		}}}
+		diagnostics := transform(t, &tr,
+			"testdata/utilerrors/utilerrors.go",
+			"testdata/goerrors/join_skipped_from_utilerrors.go")
+		if len(diagnostics) != 2 {
+			t.Errorf("len(diagnostics) wants 2 but was %d", len(diagnostics))
+		}
+	})
 }
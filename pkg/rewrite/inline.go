@@ -0,0 +1,507 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"reflect"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// inlineHelper represents an unexported function which only returns an error of the well-known package,
+// such as func wrap(err error, msg string) error { return errors.Wrap(err, msg) }.
+type inlineHelper struct {
+	decl   *ast.FuncDecl
+	file   *ast.File
+	obj    *types.Func
+	params []*types.Var
+	call   *ast.CallExpr // the result of the function
+}
+
+// inlineHelpers replaces the calls of the helper functions in the package with the body of them,
+// so that the transformer can rewrite them with the target.
+// A helper function is removed if no reference remains.
+// The files for which skip returns true are left as they are.
+// A helper function is not inlined if the call in it is marked by the ignore directive or not selected by the filter.
+//
+//	return wrap(err, "MESSAGE") -> return errors.Wrap(err, "MESSAGE")
+//
+// A helper function is kept and reported if a file in the package directory which is not loaded,
+// such as a file excluded by the build constraints, may refer to it.
+// It returns the number of changes for each file.
+func inlineHelpers(pkg *packages.Package, customs customPackages, filter *callFilter, skip func(file *ast.File) bool) (map[*ast.File]int, []Diagnostic) {
+	changes := make(map[*ast.File]int)
+	var diagnostics []Diagnostic
+	for _, h := range findInlineHelpers(pkg, customs, filter) {
+		inlined := make(map[*ast.Ident]bool)
+		for _, file := range pkg.Syntax {
+			if skip(file) {
+				continue
+			}
+			astutil.Apply(file, nil, func(c *astutil.Cursor) bool {
+				call, ok := c.Node().(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				fun, ok := call.Fun.(*ast.Ident)
+				if !ok || pkg.TypesInfo.Uses[fun] != h.obj {
+					return true
+				}
+				newCall, imports := h.inline(pkg, file, call)
+				if newCall == nil {
+					log.Printf("%s: NOTE: %s() could not be inlined", astio.Position(pkg, call), h.obj.Name())
+					return true
+				}
+				log.Printf("%s: %s() -> %s", astio.Position(pkg, call), h.obj.Name(), exprString(pkg.Fset, newCall.Fun))
+				c.Replace(newCall)
+				inlined[fun] = true
+				changes[file]++
+				for _, pkgName := range imports {
+					if addImportOf(pkg, file, pkgName) {
+						changes[file]++
+					}
+				}
+				return true
+			})
+		}
+		if len(inlined) == 0 || h.referred(pkg, inlined) || skip(h.file) {
+			continue
+		}
+		if filename := h.referredByUnloadedFile(pkg); filename != "" {
+			diagnostics = append(diagnostics, Diagnostic{
+				Position: astio.Position(pkg, h.decl),
+				Function: pkg.Types.Path() + "." + h.obj.Name(),
+				Reason:   fmt.Sprintf("the function is left as it is because %s may refer to it", filepath.Base(filename)),
+			})
+			continue
+		}
+		removeDecl(h.file, h.decl)
+		log.Printf("%s: - func %s()", astio.Position(pkg, h.decl), h.obj.Name())
+		changes[h.file]++
+		for _, p := range h.importPaths(pkg) {
+			if !astutil.UsesImport(h.file, p) && astutil.DeleteImport(pkg.Fset, h.file, p) {
+				log.Printf("%s: - import %s", astio.Filename(pkg, h.file), p)
+				changes[h.file]++
+			}
+		}
+	}
+	return changes, diagnostics
+}
+
+// findInlineHelpers returns the helper functions in the package.
+func findInlineHelpers(pkg *packages.Package, customs customPackages, filter *callFilter) []*inlineHelper {
+	var helpers []*inlineHelper
+	for _, file := range pkg.Syntax {
+		ignored := astio.IgnoredCalls(pkg, file)
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || ast.IsExported(fn.Name.Name) || fn.Name.Name == "init" || fn.Name.Name == "main" {
+				continue
+			}
+			obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			h := newInlineHelper(pkg, file, fn, obj, customs)
+			if h == nil {
+				continue
+			}
+			if ignored[h.call] || !h.selected(pkg, filter) {
+				log.Printf("%s: %s() is not inlined", astio.Position(pkg, fn), obj.Name())
+				continue
+			}
+			helpers = append(helpers, h)
+		}
+	}
+	return helpers
+}
+
+func newInlineHelper(pkg *packages.Package, file *ast.File, fn *ast.FuncDecl, obj *types.Func, customs customPackages) *inlineHelper {
+	if len(fn.Body.List) != 1 {
+		return nil
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	call, ok := ret.Results[0].(*ast.CallExpr)
+	if !ok || !isErrorPackageCall(pkg, call, customs) {
+		return nil
+	}
+	sig := obj.Type().(*types.Signature)
+	if sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), pkg.TypesInfo.TypeOf(call)) {
+		return nil
+	}
+	h := &inlineHelper{decl: fn, file: file, obj: obj, call: call}
+	for i := 0; i < sig.Params().Len(); i++ {
+		h.params = append(h.params, sig.Params().At(i))
+	}
+	// each parameter must be referred at most once, and the variadic parameter must be passed as it is
+	refs := make(map[*types.Var]int)
+	var unsupported bool
+	ast.Inspect(call, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			unsupported = true
+		case *ast.Ident:
+			if v, ok := pkg.TypesInfo.Uses[node].(*types.Var); ok && h.paramIndex(v) >= 0 {
+				refs[v]++
+			}
+		}
+		return !unsupported
+	})
+	for _, n := range refs {
+		if n > 1 {
+			unsupported = true
+		}
+	}
+	if sig.Variadic() {
+		last := h.params[len(h.params)-1]
+		if refs[last] > 0 && (!call.Ellipsis.IsValid() || pkg.TypesInfo.Uses[identOf(call.Args[len(call.Args)-1])] != last) {
+			unsupported = true
+		}
+	}
+	if unsupported {
+		return nil
+	}
+	return h
+}
+
+// isErrorPackageCall returns true if the expression is a function call of the well-known error package.
+func isErrorPackageCall(pkg *packages.Package, call *ast.CallExpr, customs customPackages) bool {
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := fun.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName)
+	if !ok {
+		return false
+	}
+	switch p := pkgName.Imported().Path(); p {
+	case "fmt":
+		return fun.Sel.Name == "Errorf"
+	case "errors", pkgErrorsImportPath, xerrorsImportPath, cockroachErrorsImportPath,
+		jujuErrorsImportPath, githubGoErrorsImportPath, erisImportPath:
+		return true
+	default:
+		return customs.find(p) != nil
+	}
+}
+
+func identOf(expr ast.Expr) *ast.Ident {
+	ident, _ := expr.(*ast.Ident)
+	return ident
+}
+
+// selected returns true if the function call in the helper is selected by the filter.
+func (h *inlineHelper) selected(pkg *packages.Package, filter *callFilter) bool {
+	fun := h.call.Fun.(*ast.SelectorExpr)
+	pkgName := pkg.TypesInfo.Uses[fun.X.(*ast.Ident)].(*types.PkgName)
+	return filter.selectedFunction(pkgName.Imported().Path(), fun.Sel.Name)
+}
+
+func (h *inlineHelper) paramIndex(v *types.Var) int {
+	for i, p := range h.params {
+		if p == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// inline returns the body of the helper function with the arguments of the call.
+// It also returns the packages which need to be imported to the file.
+// It returns nil if the call cannot be inlined.
+func (h *inlineHelper) inline(pkg *packages.Package, file *ast.File, call *ast.CallExpr) (*ast.CallExpr, []*types.PkgName) {
+	sig := h.obj.Type().(*types.Signature)
+	args := call.Args
+	var variadicArgs []ast.Expr
+	if sig.Variadic() && !call.Ellipsis.IsValid() {
+		if len(args) < len(h.params)-1 {
+			return nil, nil
+		}
+		args, variadicArgs = args[:len(h.params)-1], args[len(h.params)-1:]
+	} else if len(args) != len(h.params) {
+		// e.g. f(g()) where g returns multiple values
+		return nil, nil
+	}
+
+	// the arguments are evaluated in the order of the parameters,
+	// so they must not have any side effect if the order is changed
+	var order []int
+	ast.Inspect(h.call, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			if v, ok := pkg.TypesInfo.Uses[ident].(*types.Var); ok {
+				if i := h.paramIndex(v); i >= 0 {
+					order = append(order, i)
+				}
+			}
+		}
+		return true
+	})
+	inOrder := len(order) == len(h.params)
+	for i, o := range order {
+		if o != i {
+			inOrder = false
+		}
+	}
+	if !inOrder {
+		for _, arg := range call.Args {
+			if hasSideEffect(arg) {
+				return nil, nil
+			}
+		}
+	}
+
+	// the identifiers in the body must refer to the same objects at the call site
+	scope := pkg.Types.Scope().Innermost(call.Pos())
+	if scope == nil {
+		return nil, nil
+	}
+	var imports []*types.PkgName
+	resolvable := true
+	var checkIdents func(node ast.Node) bool
+	checkIdents = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(node.X, checkIdents)
+			return false
+		case *ast.Ident:
+			obj := pkg.TypesInfo.Uses[node]
+			if obj == nil {
+				return false
+			}
+			if v, ok := obj.(*types.Var); ok && (v.IsField() || h.paramIndex(v) >= 0) {
+				// a field is not resolved by the scope, e.g. T{Field: value}
+				return false
+			}
+			_, found := scope.LookupParent(node.Name, call.Pos())
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				if found == nil {
+					imports = append(imports, pkgName)
+					return false
+				}
+				if foundPkgName, ok := found.(*types.PkgName); ok && foundPkgName.Imported() == pkgName.Imported() {
+					return false
+				}
+				resolvable = false
+				return false
+			}
+			if found != obj {
+				resolvable = false
+			}
+		}
+		return resolvable
+	}
+	ast.Inspect(h.call, checkIdents)
+	if !resolvable {
+		return nil, nil
+	}
+
+	newCall := copyTypedExpr(pkg.TypesInfo, h.call, call.Pos()).(*ast.CallExpr)
+	newCall.Rparen = call.Rparen
+	astutil.Apply(newCall, nil, func(c *astutil.Cursor) bool {
+		ident, ok := c.Node().(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := pkg.TypesInfo.Uses[ident].(*types.Var)
+		if !ok {
+			return true
+		}
+		i := h.paramIndex(v)
+		if i < 0 {
+			return true
+		}
+		if i < len(args) {
+			c.Replace(args[i])
+		}
+		return true
+	})
+	if sig.Variadic() && !call.Ellipsis.IsValid() && newCall.Ellipsis.IsValid() {
+		// f(a, b...) -> f(a, x, y)
+		newCall.Args = append(newCall.Args[:len(newCall.Args)-1], variadicArgs...)
+		newCall.Ellipsis = token.NoPos
+	}
+	return newCall, imports
+}
+
+// importPaths returns the import paths of the packages referred by the body.
+func (h *inlineHelper) importPaths(pkg *packages.Package) []string {
+	var paths []string
+	ast.Inspect(h.call, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			if pkgName, ok := pkg.TypesInfo.Uses[ident].(*types.PkgName); ok {
+				paths = append(paths, pkgName.Imported().Path())
+			}
+		}
+		return true
+	})
+	return paths
+}
+
+// referred returns true if the helper function is referred except the inlined calls.
+func (h *inlineHelper) referred(pkg *packages.Package, inlined map[*ast.Ident]bool) bool {
+	for ident, obj := range pkg.TypesInfo.Uses {
+		if obj == h.obj && !inlined[ident] {
+			return true
+		}
+	}
+	return false
+}
+
+// referredByUnloadedFile returns the file in the package directory which is not loaded but may refer to the helper function,
+// such as a file excluded by the build constraints or a test file.
+// The file is parsed without the type information, so any identifier of the same name is regarded as a reference.
+// It returns an empty string if no file refers to it.
+func (h *inlineHelper) referredByUnloadedFile(pkg *packages.Package) string {
+	loaded := make(map[string]bool)
+	for _, file := range pkg.Syntax {
+		loaded[pkg.Fset.PositionFor(file.Package, false).Filename] = true
+	}
+	dir := filepath.Dir(pkg.Fset.PositionFor(h.file.Package, false).Filename)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+	for _, entry := range entries {
+		filename := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || filepath.Ext(filename) != ".go" || loaded[filename] {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+		if err != nil {
+			// the file may refer to it
+			return filename
+		}
+		if f.Name.Name != pkg.Types.Name() {
+			continue
+		}
+		var found bool
+		ast.Inspect(f, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && ident != f.Name && ident.Name == h.obj.Name() {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return filename
+		}
+	}
+	return ""
+}
+
+// hasSideEffect returns true if the expression may have any side effect, i.e., a function call or receive.
+func hasSideEffect(expr ast.Expr) bool {
+	var found bool
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			found = true
+		case *ast.UnaryExpr:
+			if node.Op == token.ARROW {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// addImportOf adds the import of the package to the file.
+func addImportOf(pkg *packages.Package, file *ast.File, pkgName *types.PkgName) bool {
+	p := pkgName.Imported().Path()
+	var added bool
+	if pkgName.Name() == path.Base(p) {
+		added = astutil.AddImport(pkg.Fset, file, p)
+	} else {
+		added = astutil.AddNamedImport(pkg.Fset, file, pkgName.Name(), p)
+	}
+	if added {
+		log.Printf("%s: + import %s", astio.Filename(pkg, file), p)
+	}
+	return added
+}
+
+// copyTypedExpr returns a deep copy of the expression with the type information.
+// All the valid positions are set to pos.
+func copyTypedExpr(info *types.Info, expr ast.Expr, pos token.Pos) ast.Expr {
+	return copyTypedValue(info, reflect.ValueOf(expr), pos).Interface().(ast.Expr)
+}
+
+func copyTypedValue(info *types.Info, v reflect.Value, pos token.Pos) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		switch v.Interface().(type) {
+		case *ast.Object, *ast.Scope:
+			return v
+		}
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(copyTypedValue(info, v.Elem(), pos))
+		copyTypesInfo(info, v.Interface(), c.Interface())
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyTypedValue(info, v.Elem(), pos))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyTypedValue(info, v.Index(i), pos))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Type() == reflect.TypeOf(token.NoPos) {
+				if token.Pos(f.Int()).IsValid() {
+					c.Field(i).Set(reflect.ValueOf(pos))
+				}
+				continue
+			}
+			c.Field(i).Set(copyTypedValue(info, v.Field(i), pos))
+		}
+		return c
+	}
+	return v
+}
+
+func copyTypesInfo(info *types.Info, from, to interface{}) {
+	if from, ok := from.(ast.Expr); ok {
+		if tv, ok := info.Types[from]; ok {
+			info.Types[to.(ast.Expr)] = tv
+		}
+	}
+	if from, ok := from.(*ast.Ident); ok {
+		if obj, ok := info.Uses[from]; ok {
+			info.Uses[to.(*ast.Ident)] = obj
+		}
+	}
+	if from, ok := from.(*ast.SelectorExpr); ok {
+		if s, ok := info.Selections[from]; ok {
+			info.Selections[to.(*ast.SelectorExpr)] = s
+		}
+	}
+}
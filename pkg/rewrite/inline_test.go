@@ -0,0 +1,101 @@
+package rewrite
+
+import (
+	"context"
+	"go/ast"
+	"go/printer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// inlineHelpersTransformer inlines the helper functions before the transformation.
+type inlineHelpersTransformer struct {
+	Transformer
+	filter *callFilter
+}
+
+func (t *inlineHelpersTransformer) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
+	changes, d := inlineHelpers(pkg, nil, t.filter, func(*ast.File) bool { return false })
+	m, diagnostics, err := t.Transformer.Transform(pkg, file)
+	return changes[file] + m, append(d, diagnostics...), err
+}
+
+func TestInlineHelpers(t *testing.T) {
+	log.Printf = t.Logf
+
+	t.Run("to go-errors", func(t *testing.T) {
+		tr := inlineHelpersTransformer{&toGoErrors{}, nil}
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/helper.go",
+			"testdata/goerrors/helper_inlined_from_pkgerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("skipped function", func(t *testing.T) {
+		f := &callFilter{skip: map[string]bool{pkgErrorsImportPath + ".WithStack": true}}
+		tr := inlineHelpersTransformer{&toGoErrors{filter: f}, f}
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/helper.go",
+			"testdata/goerrors/helper_skipped_from_pkgerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("to xerrors", func(t *testing.T) {
+		tr := inlineHelpersTransformer{&toXerrors{}, nil}
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/helper.go",
+			"testdata/xerrors/helper_inlined_from_pkgerrors.go")
+		if len(diagnostics) != 0 {
+			t.Errorf("len(diagnostics) wants 0 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("referred by an excluded file", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir(".", "fixture")
+		if err != nil {
+			t.Fatalf("could not create a temp dir: %s", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(tempDir); err != nil {
+				t.Errorf("could not remove the temp dir: %s", err)
+			}
+		}()
+		fixture, err := ioutil.ReadFile("testdata/pkgerrors/helper.go")
+		if err != nil {
+			t.Fatalf("could not read the fixture: %s", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(tempDir, "main.go"), fixture, 0644); err != nil {
+			t.Fatalf("could not write the fixture: %s", err)
+		}
+		excluded := "//go:build ignore\n\npackage main\n\nfunc excluded(err error) error {\n\treturn wrap(err, \"MESSAGE\")\n}\n"
+		if err := ioutil.WriteFile(filepath.Join(tempDir, "excluded.go"), []byte(excluded), 0644); err != nil {
+			t.Fatalf("could not write the excluded file: %s", err)
+		}
+		pkgs, err := astio.Load(context.TODO(), "./"+tempDir)
+		if err != nil {
+			t.Fatalf("could not load the fixture package: %s", err)
+		}
+		_, diagnostics := inlineHelpers(pkgs[0], nil, nil, func(*ast.File) bool { return false })
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+		var w strings.Builder
+		if err := printer.Fprint(&w, pkgs[0].Fset, pkgs[0].Syntax[0]); err != nil {
+			t.Fatalf("could not print the AST: %s", err)
+		}
+		if !strings.Contains(w.String(), "func wrap(") {
+			t.Errorf("wrap() wants to be kept")
+		}
+		if strings.Contains(w.String(), "func wrapf(") {
+			t.Errorf("wrapf() wants to be removed")
+		}
+	})
+}
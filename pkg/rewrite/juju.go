@@ -0,0 +1,400 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// jujuErrorKinds are the error types of github.com/juju/errors.
+// Each kind has the constructor Xf(), the predicate IsX() and the constant X.
+var jujuErrorKinds = []string{
+	"Timeout",
+	"NotFound",
+	"UserNotFound",
+	"Unauthorized",
+	"NotImplemented",
+	"AlreadyExists",
+	"NotSupported",
+	"NotValid",
+	"NotProvisioned",
+	"NotAssigned",
+	"BadRequest",
+	"MethodNotAllowed",
+	"Forbidden",
+	"QuotaLimitExceeded",
+	"NotYetAvailable",
+}
+
+func isJujuErrorKind(name string) bool {
+	for _, kind := range jujuErrorKinds {
+		if kind == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sentinelPackage represents the package of sentinel errors,
+// which replace the error types of github.com/juju/errors.
+// It is shared by the transformers of all files,
+// and written after the transformation if any file refers to it.
+type sentinelPackage struct {
+	Path string // import path, e.g. github.com/int128/errto/errkind
+	Dir  string // directory of the package
+	used bool
+}
+
+// newSentinelPackage returns the sentinel package in the module.
+// If importPath is empty, errkind package in the module root is used.
+// It returns nil if the module is not found and importPath is empty.
+func newSentinelPackage(m *astio.Module, importPath string) (*sentinelPackage, error) {
+	if m == nil {
+		if importPath == "" {
+			log.Printf("NOTE: the error types of %s cannot be rewritten outside a module", jujuErrorsImportPath)
+			return nil, nil
+		}
+		return nil, errors.New("module not found")
+	}
+	if importPath == "" {
+		importPath = m.Path + "/errkind"
+	}
+	if !token.IsIdentifier(path.Base(importPath)) {
+		return nil, fmt.Errorf("the last element of %s must be a valid package name", importPath)
+	}
+	if importPath != m.Path && !strings.HasPrefix(importPath, m.Path+"/") {
+		return nil, fmt.Errorf("%s must be in the module %s", importPath, m.Path)
+	}
+	return &sentinelPackage{
+		Path: importPath,
+		Dir:  filepath.Join(m.Dir, filepath.FromSlash(strings.TrimPrefix(importPath, m.Path))),
+	}, nil
+}
+
+func (s *sentinelPackage) name() string {
+	return path.Base(s.Path)
+}
+
+// sentinelName returns the name of the sentinel error of the kind, e.g. ErrNotFound.
+func sentinelName(kind string) string {
+	return "Err" + kind
+}
+
+// sentinelMessage returns the message of the sentinel error of the kind, e.g. "not found".
+func sentinelMessage(kind string) string {
+	var b strings.Builder
+	for i, r := range kind {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune(' ')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// write writes the sentinel errors of all the kinds to the package.
+// If the file already exists, the sentinel errors which are not declared in the package are appended to it.
+func (s *sentinelPackage) write() error {
+	filename := filepath.Join(s.Dir, s.name()+".go")
+	if _, err := os.Stat(filename); err == nil {
+		return s.merge(filename)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Package %s provides the sentinel errors which replace the error types of %s.\n", s.name(), jujuErrorsImportPath)
+	fmt.Fprintf(&b, "// Use errors.Is() to check the kind of an error.\n")
+	fmt.Fprintf(&b, "package %s\n\n", s.name())
+	fmt.Fprintf(&b, "import \"errors\"\n\n")
+	writeSentinelDecl(&b, jujuErrorKinds)
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("could not format the source: %w", err)
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("could not create the directory: %w", err)
+	}
+	log.Printf("--- writing sentinel package %s to %s", s.Path, filename)
+	if err := ioutil.WriteFile(filename, src, 0644); err != nil {
+		return fmt.Errorf("could not write the file: %w", err)
+	}
+	return nil
+}
+
+// merge appends the sentinel errors which are not declared in the package to the existing file.
+// It returns an error if the file imports another package of the name errors.
+func (s *sentinelPackage) merge(filename string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, s.Dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("could not parse the sentinel package: %w", err)
+	}
+	var file *ast.File
+	declared := make(map[string]bool)
+	for _, p := range pkgs {
+		for name, f := range p.Files {
+			if name == filename {
+				file = f
+			}
+			for _, decl := range f.Decls {
+				for _, ident := range declaredNames(decl) {
+					declared[ident.Name] = true
+				}
+			}
+		}
+	}
+	if file == nil {
+		return fmt.Errorf("could not find %s in the sentinel package", filename)
+	}
+	var missing []string
+	for _, kind := range jujuErrorKinds {
+		if !declared[sentinelName(kind)] {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) == 0 {
+		log.Printf("--- sentinel package %s already exists", s.Path)
+		return nil
+	}
+	for _, spec := range file.Imports {
+		p, name := importPath(spec), path.Base(importPath(spec))
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if p != "errors" && name == "errors" {
+			return fmt.Errorf("could not add the sentinel errors to %s: errors refers to %s", filename, p)
+		}
+	}
+	astutil.AddImport(fset, file, "errors")
+	var b strings.Builder
+	if err := format.Node(&b, fset, file); err != nil {
+		return fmt.Errorf("could not print the file: %w", err)
+	}
+	fmt.Fprintf(&b, "\n")
+	writeSentinelDecl(&b, missing)
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("could not format the source: %w", err)
+	}
+	log.Printf("--- adding %d sentinel error(s) to %s", len(missing), filename)
+	if err := ioutil.WriteFile(filename, src, 0644); err != nil {
+		return fmt.Errorf("could not write the file: %w", err)
+	}
+	return nil
+}
+
+// writeSentinelDecl writes the declaration of the sentinel errors of the kinds.
+func writeSentinelDecl(b *strings.Builder, kinds []string) {
+	fmt.Fprintf(b, "var (\n")
+	for _, kind := range kinds {
+		fmt.Fprintf(b, "%s = errors.New(%q)\n", sentinelName(kind), sentinelMessage(kind))
+	}
+	fmt.Fprintf(b, ")\n")
+}
+
+// declaredNames returns the names declared by the top-level declaration.
+func declaredNames(decl ast.Decl) []*ast.Ident {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv == nil {
+			return []*ast.Ident{decl.Name}
+		}
+	case *ast.GenDecl:
+		var names []*ast.Ident
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				names = append(names, spec.Names...)
+			case *ast.TypeSpec:
+				names = append(names, spec.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// jujuErrors rewrites the function calls of github.com/juju/errors
+// by the equivalent function calls of github.com/pkg/errors.
+// It is embedded in the visitors.
+type jujuErrors struct {
+	sentinels          *sentinelPackage
+	errorfWraps        bool // Errorf of the target wraps an error by %w in the middle of the format
+	needSentinelImport int
+}
+
+// jujuErrorsFunctionCall renames the function call of github.com/juju/errors
+// to the equivalent function of github.com/pkg/errors, and calls pkgErrorsFunctionCall.
+// If pkgErrorsFunctionCall returns an error, the function call is restored.
+//
+//	Annotate(err, "MESSAGE")           -> Wrap(err, "MESSAGE")
+//	Annotatef(err, "FORMAT", ...)      -> Wrapf(err, "FORMAT", ...)
+//	Trace(err)                         -> WithStack(err)
+//	NotFoundf("FORMAT", ...)           -> Errorf("FORMAT %w", ..., errkind.ErrNotFound)
+//	IsNotFound(err)                    -> Is(err, errkind.ErrNotFound)
+//
+// NotFoundf() appends the message of the kind to the format, e.g. "user 1 not found".
+// It is rewritten only if Errorf of the target wraps an error by %w in the middle of the format,
+// because Wrapf joins the messages with a colon, e.g. "user 1: not found".
+func (j *jujuErrors) jujuErrorsFunctionCall(call astio.PackageFunctionCall, pkgErrorsFunctionCall func(call astio.PackageFunctionCall) error) error {
+	name, args := call.FunctionName(), call.Args()
+	var usesSentinel bool
+	switch {
+	case name == "New" || name == "Errorf" || name == "Cause" || name == "Unwrap" || name == "As" || name == "Is":
+	case name == "Annotate":
+		call.TargetFun.Sel.Name = "Wrap"
+	case name == "Annotatef":
+		call.TargetFun.Sel.Name = "Wrapf"
+	case name == "Trace":
+		call.TargetFun.Sel.Name = "WithStack"
+
+	case strings.HasSuffix(name, "f") && isJujuErrorKind(strings.TrimSuffix(name, "f")):
+		if !j.errorfWraps {
+			return errors.New("the target cannot append the message of the kind without a colon")
+		}
+		if len(args) < 1 {
+			return fmt.Errorf("errors.%s expects 1 or more arguments but has %d arguments", name, len(args))
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
+		format, ok := args[0].(*ast.BasicLit)
+		if !ok || format.Kind != token.STRING {
+			return fmt.Errorf("1st argument of %s must be a string literal", name)
+		}
+		value, err := strconv.Unquote(format.Value)
+		if err != nil {
+			return fmt.Errorf("invalid format: %w", err)
+		}
+		sentinel, err := j.sentinel(strings.TrimSuffix(name, "f"), args[len(args)-1].End())
+		if err != nil {
+			return err
+		}
+		// the original literal is kept, so that it can be restored
+		newFormat := &ast.BasicLit{ValuePos: format.ValuePos, Kind: token.STRING, Value: strconv.Quote(value + " %w")}
+		var newArgs []ast.Expr
+		newArgs = append(newArgs, newFormat)
+		newArgs = append(newArgs, args[1:]...)
+		newArgs = append(newArgs, sentinel)
+		call.SetArgs(newArgs)
+		call.TargetFun.Sel.Name = "Errorf"
+		usesSentinel = true
+
+	case strings.HasPrefix(name, "Is") && isJujuErrorKind(strings.TrimPrefix(name, "Is")):
+		if len(args) != 1 {
+			return fmt.Errorf("errors.%s expects 1 argument but has %d arguments", name, len(args))
+		}
+		sentinel, err := j.sentinel(strings.TrimPrefix(name, "Is"), args[0].End())
+		if err != nil {
+			return err
+		}
+		call.SetArgs([]ast.Expr{args[0], sentinel})
+		call.TargetFun.Sel.Name = "Is"
+		usesSentinel = true
+
+	default:
+		return errUnsupportedFunction
+	}
+
+	if err := pkgErrorsFunctionCall(call); err != nil {
+		call.TargetFun.Sel.Name = name
+		call.SetArgs(args)
+		return err
+	}
+	if usesSentinel {
+		j.sentinels.used = true
+		j.needSentinelImport++
+	}
+	return nil
+}
+
+// sentinel returns the expression of the sentinel error, e.g. errkind.ErrNotFound.
+func (j *jujuErrors) sentinel(kind string, pos token.Pos) (ast.Expr, error) {
+	if j.sentinels == nil {
+		return nil, errors.New("no sentinel package is available for the error type")
+	}
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{NamePos: pos, Name: j.sentinels.name()},
+		Sel: &ast.Ident{NamePos: pos, Name: sentinelName(kind)},
+	}, nil
+}
+
+// replaceJujuConstErrors rewrites the constants of the error types to the sentinel errors.
+//
+//	errors.Is(err, errors.NotFound) -> errors.Is(err, errkind.ErrNotFound)
+//
+// If the sentinel package is not available, it reports the constants
+// and keeps the import of github.com/juju/errors.
+// The arguments of a call marked by the ignore directive or not selected by the filter are left as they are.
+// It returns the number of changes.
+func (j *jujuErrors) replaceJujuConstErrors(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, filter *callFilter, r *reporter) int {
+	var n int
+	astutil.Apply(file, func(c *astutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.CallExpr:
+			if ignored[node] {
+				return false
+			}
+			if fun, ok := node.Fun.(*ast.SelectorExpr); ok {
+				if obj, ok := pkg.TypesInfo.Uses[fun.Sel].(*types.Func); ok && obj.Pkg() != nil && obj.Pkg().Path() == jujuErrorsImportPath {
+					return filter.selectedFunction(jujuErrorsImportPath, obj.Name())
+				}
+			}
+		case *ast.SelectorExpr:
+			obj, ok := pkg.TypesInfo.Uses[node.Sel].(*types.Const)
+			if !ok || obj.Pkg() == nil || obj.Pkg().Path() != jujuErrorsImportPath || !isJujuErrorKind(obj.Name()) {
+				return true
+			}
+			sentinel, err := j.sentinel(obj.Name(), node.Pos())
+			if err != nil {
+				r.add(Diagnostic{
+					Position: astio.Position(pkg, node),
+					Function: jujuErrorsImportPath + "." + obj.Name(),
+					Reason:   err.Error(),
+				})
+				r.keepImport(jujuErrorsImportPath)
+				return false
+			}
+			log.Printf("%s: errors.%s -> %s", astio.Position(pkg, node), obj.Name(), exprString(pkg.Fset, sentinel))
+			c.Replace(sentinel)
+			j.sentinels.used = true
+			j.needSentinelImport++
+			n++
+			return false
+		}
+		return true
+	}, nil)
+	return n
+}
+
+// addSentinelImport adds the import of the sentinel package if needed.
+// It returns the number of changes.
+func (j *jujuErrors) addSentinelImport(pkg *packages.Package, file *ast.File) int {
+	if j.needSentinelImport == 0 {
+		return 0
+	}
+	if !astutil.AddImport(pkg.Fset, file, j.sentinels.Path) {
+		return 0
+	}
+	log.Printf("%s: + import %s", astio.Filename(pkg, file), j.sentinels.Path)
+	return 1
+}
@@ -0,0 +1,85 @@
+package rewrite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSentinelPackage_write(t *testing.T) {
+	t.Run("new package", func(t *testing.T) {
+		s := newTestSentinelPackage(t)
+		if err := s.write(); err != nil {
+			t.Fatalf("write error: %s", err)
+		}
+		got := readSentinelFile(t, s)
+		for _, kind := range jujuErrorKinds {
+			if !strings.Contains(got, sentinelName(kind)+" ") {
+				t.Errorf("%s wants to be declared", sentinelName(kind))
+			}
+		}
+	})
+	t.Run("missing sentinels", func(t *testing.T) {
+		s := newTestSentinelPackage(t)
+		writeSentinelFile(t, s, `package errkind
+
+import "fmt"
+
+var ErrNotFound = fmt.Errorf("not found")
+`)
+		if err := s.write(); err != nil {
+			t.Fatalf("write error: %s", err)
+		}
+		got := readSentinelFile(t, s)
+		if n := strings.Count(got, "ErrNotFound "); n != 1 {
+			t.Errorf("ErrNotFound wants to be declared once but was %d times", n)
+		}
+		if !strings.Contains(got, `errors.New("already exists")`) {
+			t.Errorf("ErrAlreadyExists wants to be added but was:\n%s", got)
+		}
+		if !strings.Contains(got, `"errors"`) {
+			t.Errorf("errors wants to be imported but was:\n%s", got)
+		}
+	})
+	t.Run("conflict", func(t *testing.T) {
+		s := newTestSentinelPackage(t)
+		writeSentinelFile(t, s, `package errkind
+
+import "github.com/pkg/errors"
+
+var ErrNotFound = errors.New("not found")
+`)
+		if err := s.write(); err == nil {
+			t.Errorf("write wants an error")
+		}
+	})
+}
+
+func newTestSentinelPackage(t *testing.T) *sentinelPackage {
+	tempDir, err := ioutil.TempDir("", "sentinel")
+	if err != nil {
+		t.Fatalf("could not create a temp dir: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			t.Errorf("could not remove the temp dir: %s", err)
+		}
+	})
+	return &sentinelPackage{Path: "example.com/errkind", Dir: tempDir}
+}
+
+func writeSentinelFile(t *testing.T, s *sentinelPackage, content string) {
+	if err := ioutil.WriteFile(filepath.Join(s.Dir, "errkind.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("could not write the file: %s", err)
+	}
+}
+
+func readSentinelFile(t *testing.T, s *sentinelPackage) string {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, "errkind.go"))
+	if err != nil {
+		t.Fatalf("could not read the file: %s", err)
+	}
+	return string(b)
+}
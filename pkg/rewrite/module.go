@@ -0,0 +1,161 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// moduleDirs returns the directories to load the packages in.
+// If allModules is true, it returns the modules in the workspace of the current directory.
+// Otherwise, it returns the current directory as an empty string.
+func moduleDirs(allModules bool) ([]string, error) {
+	if !allModules {
+		return []string{""}, nil
+	}
+	dirs, err := astio.FindModuleDirs(".")
+	if err != nil {
+		return nil, fmt.Errorf("could not find the modules: %w", err)
+	}
+	if len(dirs) == 0 {
+		return nil, errors.New("no module found")
+	}
+	return dirs, nil
+}
+
+// moduleOption represents the options of a module,
+// such as the target detected from the module and the features of the Go version.
+type moduleOption struct {
+	root      string // absolute path to the module root
+	target    Method
+	opt       transformerOption
+	sentinels *sentinelPackage
+}
+
+// moduleOptions holds the options of the modules which contain the packages.
+type moduleOptions struct {
+	in      Input
+	files   *fileFilter // files to leave as they are, which are not counted by the detection
+	modules []*moduleOption
+	roots   map[string]*moduleOption // key is the module root
+	dirs    map[string]*moduleOption // key is the directory of a package
+}
+
+func newModuleOptions(in Input, files *fileFilter) *moduleOptions {
+	return &moduleOptions{
+		in:    in,
+		files: files,
+		roots: make(map[string]*moduleOption),
+		dirs:  make(map[string]*moduleOption),
+	}
+}
+
+// prepare determines the options of the modules which contain the selected files.
+// It must be called before rewriting the packages, because the target may be detected from them.
+func (mo *moduleOptions) prepare(pkgs []*packages.Package, selected map[*ast.File]bool) error {
+	var d *Detection
+	for _, pkg := range pkgs {
+		if len(pkg.CompiledGoFiles) == 0 || !hasSelectedFile(pkg, selected) {
+			continue
+		}
+		dir := filepath.Dir(pkg.CompiledGoFiles[0])
+		if mo.dirs[dir] != nil {
+			continue
+		}
+		m := findModule(dir)
+		root, err := moduleRoot(m)
+		if err != nil {
+			return err
+		}
+		o := mo.roots[root]
+		if o == nil {
+			if mo.in.Target == Auto && d == nil {
+				d = detect(pkgs, customPackages(mo.in.CustomPackages), mo.files)
+			}
+			o, err = newModuleOption(mo.in, m, root, d)
+			if err != nil {
+				return err
+			}
+			mo.roots[root] = o
+			mo.modules = append(mo.modules, o)
+		}
+		mo.dirs[dir] = o
+	}
+	return nil
+}
+
+// of returns the options of the module which contains the package.
+func (mo *moduleOptions) of(pkg *packages.Package) *moduleOption {
+	if len(pkg.CompiledGoFiles) == 0 {
+		return nil
+	}
+	return mo.dirs[filepath.Dir(pkg.CompiledGoFiles[0])]
+}
+
+func newModuleOption(in Input, m *astio.Module, root string, d *Detection) (*moduleOption, error) {
+	if in.Target == Auto {
+		target := d.targetOf(m)
+		if m != nil {
+			log.Printf("--- detected the target %s for module %s", target, m.Path)
+		} else {
+			log.Printf("--- detected the target %s for %s", target, root)
+		}
+		var err error
+		in.Target, in.CustomTarget, err = parseTarget(target, customPackages(in.CustomPackages))
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the target: %w", err)
+		}
+	}
+	sentinels, err := newSentinelPackage(m, in.SentinelPackage)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the sentinel package: %w", err)
+	}
+	opt, err := newTransformerOption(in, m, sentinels)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleOption{root: root, target: in.Target, opt: opt, sentinels: sentinels}, nil
+}
+
+// targetOf returns the recommended target for the module.
+// It returns the target for all modules if the module is not detected.
+func (d *Detection) targetOf(m *astio.Module) string {
+	for _, mu := range d.Modules {
+		if mu.Module == nil && m == nil {
+			return mu.Target
+		}
+		if mu.Module != nil && m != nil && mu.Module.Dir == m.Dir {
+			return mu.Target
+		}
+	}
+	return d.Target
+}
+
+// moduleRoot returns the absolute path to the module root.
+// It returns the current directory if the module is not found.
+func moduleRoot(m *astio.Module) (string, error) {
+	if m != nil {
+		return m.Dir, nil
+	}
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return "", fmt.Errorf("could not determine the current directory: %w", err)
+	}
+	return dir, nil
+}
+
+// findModule returns the module which contains the directory.
+// It returns nil if the module is not found.
+func findModule(dir string) *astio.Module {
+	m, err := astio.FindModule(dir)
+	if err != nil {
+		log.Printf("NOTE: could not find the module of %s: %s", astio.Relative(dir), err)
+		return nil
+	}
+	return m
+}
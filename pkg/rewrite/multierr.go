@@ -0,0 +1,359 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// unwrapErrorsFuncName is the name of the function which replaces multierr.Errors().
+const unwrapErrorsFuncName = "unwrapErrors"
+
+const unwrapErrorsFuncDecl = `func unwrapErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		errs = append(errs, unwrapErrors(e)...)
+	}
+	return errs
+}
+`
+
+// combineErrorsFuncName is the name of the function which replaces multierr.Append() and multierr.Combine().
+const combineErrorsFuncName = "combineErrors"
+
+// combineErrorsFuncDecl returns the error as it is if only one error is non-nil, like multierr.Combine().
+const combineErrorsFuncDecl = `func combineErrors(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	if len(nonNil) == 1 {
+		return nonNil[0]
+	}
+	return errors.Join(nonNil...)
+}
+`
+
+// migrateMultiErrors rewrites the syntax of go.uber.org/multierr and github.com/hashicorp/go-multierror,
+// which is not a package function call, to the syntax of errors.Join().
+//
+// It rewrites the following syntax:
+//
+//	multierr.Errors(err)     -> unwrapErrors(err)
+//	*multierror.Error        -> error (in a declaration of variable, field, parameter or result)
+//	merr.ErrorOrNil()        -> merr
+//	merr.WrappedErrors()     -> unwrapErrors(merr)
+//	merr.Errors              -> unwrapErrors(merr) (if it is not assigned)
+//
+// errors.Join() returns nil if all errors are nil,
+// and therefore merr.ErrorOrNil() is no longer needed.
+// The function unwrapErrors() is added to the package if needed.
+// It flattens the joined errors like multierr.Errors().
+//
+// If a variable or field uses the other fields or methods such as ErrorFormat,
+// its declaration is reported and left as it is, and so are the uses of it.
+// So does it for the fields and methods which are not selected by the filter.
+// It returns the calls of multierror.Append() which are passed or assigned to them,
+// so that the visitor leaves them as they are.
+//
+// The other fields, methods and types of them are reported.
+// The package function calls such as multierror.Append() are rewritten by the visitor.
+// The packages which are not selected as the source are left as they are.
+// It returns the number of changes.
+func migrateMultiErrors(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, filter *callFilter, r *reporter) (int, map[*ast.CallExpr]bool) {
+	canUnwrap := pkg.Types.Scope().Lookup(unwrapErrorsFuncName) == nil
+	multierr, multierror := filter.selected(multierrImportPath), filter.selected(multierrorImportPath)
+	var kept map[types.Object]bool
+	var keptCalls map[*ast.CallExpr]bool
+	if multierror {
+		kept, keptCalls = keptMultierrorDecls(pkg, file, filter, canUnwrap)
+	}
+	var n int
+	var needUnwrapErrors bool
+	report := func(node ast.Node, name, reason string) {
+		r.add(Diagnostic{
+			Position: astio.Position(pkg, node),
+			Function: multierrorImportPath + ".Error." + name,
+			Reason:   reason,
+		})
+		addTODOComment(file, node, fmt.Sprintf("rewrite %s manually: %s", exprString(pkg.Fset, node.(ast.Expr)), reason))
+		n++
+	}
+	// replace the types before the children are visited
+	pre := func(c *astutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.StarExpr:
+			if !multierror || !isMultierrorErrorTypeExpr(pkg, node.X) {
+				return true
+			}
+			var names []*ast.Ident
+			switch parent := c.Parent().(type) {
+			case *ast.ValueSpec:
+				names = parent.Names
+			case *ast.Field:
+				names = parent.Names
+			default:
+				return true
+			}
+			for _, name := range names {
+				if kept[pkg.TypesInfo.Defs[name]] {
+					r.reportRef(astio.Position(pkg, node), node.X.(*ast.SelectorExpr), multierrorImportPath,
+						fmt.Errorf("%s uses the field or method which is left as it is", name.Name))
+					return false
+				}
+			}
+			log.Printf("%s: *multierror.Error -> error", astio.Position(pkg, node))
+			c.Replace(&ast.Ident{NamePos: node.Pos(), Name: "error"})
+			n++
+			return false
+		case *ast.SelectorExpr:
+			x, ok := node.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName)
+			if !ok || pkgName.Imported().Path() != multierrorImportPath || !multierror {
+				return true
+			}
+			if _, ok := pkg.TypesInfo.Uses[node.Sel].(*types.TypeName); ok {
+				r.reportRef(astio.Position(pkg, node), node, multierrorImportPath, errors.New("the type is not available in errors.Join"))
+			}
+			return false
+		}
+		return true
+	}
+	// replace the expressions after the children are visited, so that the children are kept
+	post := func(c *astutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.CallExpr:
+			fun, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if x, ok := fun.X.(*ast.Ident); ok && fun.Sel.Name == "Errors" && len(node.Args) == 1 && canUnwrap && multierr && !ignored[node] &&
+				filter.selectedFunction(multierrImportPath, "Errors") {
+				if pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); ok && pkgName.Imported().Path() == multierrImportPath {
+					log.Printf("%s: %s.Errors() -> %s()", astio.Position(pkg, node), x.Name, unwrapErrorsFuncName)
+					c.Replace(newFuncCall(unwrapErrorsFuncName, node.Args[0]))
+					needUnwrapErrors = true
+					n++
+					return true
+				}
+			}
+			s, ok := pkg.TypesInfo.Selections[fun]
+			if !ok || s.Kind() != types.MethodVal || !multierror || !isMultierrorError(s.Recv()) {
+				return true
+			}
+			if kept[multierrorObjectOf(pkg, fun.X)] || !filter.selectedFunction(multierrorImportPath, "Error."+fun.Sel.Name) {
+				return true
+			}
+			switch fun.Sel.Name {
+			case "Error":
+				return true
+			case "ErrorOrNil":
+				log.Printf("%s: ErrorOrNil() is removed", astio.Position(pkg, node))
+				c.Replace(fun.X)
+				n++
+				return true
+			case "WrappedErrors":
+				if canUnwrap {
+					log.Printf("%s: WrappedErrors() -> %s()", astio.Position(pkg, node), unwrapErrorsFuncName)
+					c.Replace(newFuncCall(unwrapErrorsFuncName, fun.X))
+					needUnwrapErrors = true
+					n++
+					return true
+				}
+			}
+			report(node, fun.Sel.Name, "the method is not available in errors.Join")
+
+		case *ast.SelectorExpr:
+			s, ok := pkg.TypesInfo.Selections[node]
+			if !ok || s.Kind() != types.FieldVal || !multierror || !isMultierrorError(s.Recv()) {
+				return true
+			}
+			if kept[multierrorObjectOf(pkg, node.X)] || !filter.selectedFunction(multierrorImportPath, "Error."+node.Sel.Name) {
+				return true
+			}
+			if node.Sel.Name == "Errors" && canUnwrap && !isAssigned(c) {
+				log.Printf("%s: Errors -> %s()", astio.Position(pkg, node), unwrapErrorsFuncName)
+				c.Replace(newFuncCall(unwrapErrorsFuncName, node.X))
+				needUnwrapErrors = true
+				n++
+				return true
+			}
+			report(node, node.Sel.Name, "the field is not available in errors.Join")
+		}
+		return true
+	}
+	astutil.Apply(file, pre, post)
+	if needUnwrapErrors {
+		n += addFuncDecl(pkg, file, unwrapErrorsFuncName, unwrapErrorsFuncDecl)
+	}
+	return n, keptCalls
+}
+
+// keptMultierrorDecls returns the variables and fields of *multierror.Error which cannot be rewritten to error,
+// because a field or method which is not available in errors.Join or not selected by the filter is used through them.
+// It also returns the calls of multierror.Append() which are passed or assigned to them,
+// or of which the field or method is used.
+func keptMultierrorDecls(pkg *packages.Package, file *ast.File, filter *callFilter, canUnwrap bool) (map[types.Object]bool, map[*ast.CallExpr]bool) {
+	kept := make(map[types.Object]bool)
+	keptCalls := make(map[*ast.CallExpr]bool)
+	astutil.Apply(file, nil, func(c *astutil.Cursor) bool {
+		sel, ok := c.Node().(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		s, ok := pkg.TypesInfo.Selections[sel]
+		if !ok || !isMultierrorError(s.Recv()) {
+			return true
+		}
+		switch {
+		case !filter.selectedFunction(multierrorImportPath, "Error."+sel.Sel.Name):
+			// the field or method is left as it is
+		case s.Kind() == types.MethodVal:
+			if _, ok := c.Parent().(*ast.CallExpr); ok && c.Name() == "Fun" {
+				switch sel.Sel.Name {
+				case "Error", "ErrorOrNil":
+					return true
+				case "WrappedErrors":
+					if canUnwrap {
+						return true
+					}
+				}
+			}
+		case s.Kind() == types.FieldVal:
+			if sel.Sel.Name == "Errors" && canUnwrap && !isAssigned(c) {
+				return true
+			}
+		}
+		if obj := multierrorObjectOf(pkg, sel.X); obj != nil {
+			kept[obj] = true
+		}
+		if call, ok := packageFunctionCallOf(pkg.TypesInfo, astutil.Unparen(sel.X), multierrorImportPath, "Append"); ok {
+			keptCalls[call] = true
+		}
+		return true
+	})
+	keepAssigned := func(lhs []ast.Expr, rhs []ast.Expr) {
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i := range lhs {
+			if !kept[multierrorObjectOf(pkg, lhs[i])] {
+				continue
+			}
+			if call, ok := packageFunctionCallOf(pkg.TypesInfo, rhs[i], multierrorImportPath, "Append"); ok {
+				keptCalls[call] = true
+			}
+		}
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if _, ok := packageFunctionCallOf(pkg.TypesInfo, node, multierrorImportPath, "Append"); !ok {
+				return true
+			}
+			for _, arg := range node.Args {
+				if kept[multierrorObjectOf(pkg, arg)] {
+					keptCalls[node] = true
+				}
+			}
+		case *ast.AssignStmt:
+			keepAssigned(node.Lhs, node.Rhs)
+		case *ast.ValueSpec:
+			var lhs []ast.Expr
+			for _, name := range node.Names {
+				lhs = append(lhs, name)
+			}
+			keepAssigned(lhs, node.Values)
+		}
+		return true
+	})
+	return kept, keptCalls
+}
+
+// multierrorObjectOf returns the variable or field which the expression refers to, such as merr or s.merr.
+// It returns nil if the expression is not a reference.
+func multierrorObjectOf(pkg *packages.Package, expr ast.Expr) types.Object {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		return pkg.TypesInfo.ObjectOf(expr)
+	case *ast.SelectorExpr:
+		return pkg.TypesInfo.ObjectOf(expr.Sel)
+	}
+	return nil
+}
+
+// newFuncCall returns a call of the function in the package with the argument.
+func newFuncCall(name string, arg ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:    &ast.Ident{NamePos: arg.Pos(), Name: name},
+		Lparen: arg.Pos(),
+		Args:   []ast.Expr{arg},
+		Rparen: arg.End(),
+	}
+}
+
+// addFuncDecl adds the function to the end of the file, if the package does not have it.
+// It returns the number of changes.
+func addFuncDecl(pkg *packages.Package, file *ast.File, name, src string) int {
+	if hasFuncDecl(pkg, name) {
+		return 0
+	}
+	// place it at the end of the file, so that the positions of the other nodes are kept in order
+	file.Decls = append(file.Decls, newFuncDecl(pkg.Fset, src, file.End()))
+	log.Printf("%s: + func %s()", astio.Filename(pkg, file), name)
+	return 1
+}
+
+// isMultierrorError returns true if the type is github.com/hashicorp/go-multierror.Error or a pointer of it.
+func isMultierrorError(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == multierrorImportPath && named.Obj().Name() == "Error"
+}
+
+// isMultierrorErrorTypeExpr returns true if the expression refers to the type multierror.Error.
+func isMultierrorErrorTypeExpr(pkg *packages.Package, expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	obj, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.TypeName)
+	return ok && isMultierrorError(obj.Type())
+}
+
+// isAssigned returns true if the node of the cursor is assigned or referred by the address.
+func isAssigned(c *astutil.Cursor) bool {
+	switch parent := c.Parent().(type) {
+	case *ast.AssignStmt:
+		return c.Name() == "Lhs"
+	case *ast.IncDecStmt:
+		return true
+	case *ast.UnaryExpr:
+		return parent.Op == token.AND
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package rewrite
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// errorsPackagePaths are the packages which may be renamed to the target by the transformers.
+var errorsPackagePaths = map[string]bool{
+	"fmt":                     true,
+	"errors":                  true,
+	xerrorsImportPath:         true,
+	pkgErrorsImportPath:       true,
+	cockroachErrorsImportPath: true,
+	jujuErrorsImportPath:      true,
+	githubGoErrorsImportPath:  true,
+	erisImportPath:            true,
+}
+
+// normalizeResult represents the changes by normalizeErrorf.
+type normalizeResult struct {
+	changes  int
+	newCalls int
+}
+
+// normalizeErrorf simplifies the Errorf function calls of the target after the transform.
+// errorfPath is the import path of Errorf in the target.
+// errorfPkgName and newPkgName are the package names of Errorf and New in the target.
+//
+// It rewrites Errorf("MESSAGE") without any verb to New("MESSAGE"),
+// and Errorf("FORMAT: %s", err.Error()) to Errorf("FORMAT: %s", err).
+//
+// Only the function calls produced by the transform are rewritten.
+// The function calls which already belong to errorfPath before the transform are left as they are.
+func normalizeErrorf(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, filter *callFilter, errorfPath, errorfPkgName, newPkgName string) normalizeResult {
+	var r normalizeResult
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || ignored[call] {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || fun.Sel.Name != "Errorf" {
+			return true
+		}
+		x, ok := fun.X.(*ast.Ident)
+		if !ok || x.Name != errorfPkgName {
+			return true
+		}
+		// the identifier still refers to the original package if it has been rewritten
+		pkgName, ok := pkg.TypesInfo.ObjectOf(x).(*types.PkgName)
+		if !ok || !errorsPackagePaths[pkgName.Imported().Path()] || !filter.selectedFunction(pkgName.Imported().Path(), fun.Sel.Name) {
+			return true
+		}
+		if pkgName.Imported().Path() == errorfPath {
+			return true
+		}
+		if call.Ellipsis.IsValid() {
+			return true
+		}
+		p := astio.Position(pkg, call)
+
+		args, n := stripErrorCalls(pkg.TypesInfo, call.Args)
+		if n > 0 {
+			log.Printf("%s: %s.Errorf(): removed %d redundant Error() call(s)", p, errorfPkgName, n)
+			call.Args = args
+			r.changes += n
+		}
+
+		if len(call.Args) != 1 {
+			return true
+		}
+		format, ok := stringLiteral(call.Args[0])
+		if !ok {
+			return true
+		}
+		if verbs, ok := parseFormat(format); !ok || len(verbs) > 0 {
+			return true
+		}
+		if strings.Contains(format, "%%") {
+			call.Args = []ast.Expr{newStringLiteral(strings.ReplaceAll(format, "%%", "%"))}
+		}
+		log.Printf("%s: %s.Errorf() -> %s.New()", p, errorfPkgName, newPkgName)
+		x.Name = newPkgName
+		fun.Sel.Name = "New"
+		r.changes++
+		r.newCalls++
+		return true
+	})
+	return r
+}
@@ -0,0 +1,102 @@
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+// fileOutput represents the rewritten content of a physical file.
+type fileOutput struct {
+	path     string // absolute path to the source file
+	root     string // absolute path to the module root
+	filename string // relative path for logging
+	content  []byte // nil if no change
+	changes  int
+	config   astio.BuildConfig // the configuration which rewrote the file first
+	conflict bool
+}
+
+// outputs collects the rewritten files of the build configurations and the test variants,
+// so that each physical file is written once.
+// If the variants of a file disagree, the file is left as it is.
+type outputs struct {
+	files []*fileOutput
+	index map[string]*fileOutput
+}
+
+// add adds the content of the file.
+// It returns true if the content newly conflicts with the other variant.
+func (o *outputs) add(pkg *packages.Package, file *ast.File, changes int, c astio.BuildConfig, root string) (bool, error) {
+	filename := astio.Filename(pkg, file)
+	var content []byte
+	if changes > 0 {
+		var b bytes.Buffer
+		if err := printer.Fprint(&b, pkg.Fset, file); err != nil {
+			return false, fmt.Errorf("could not print %s: %w", filename, err)
+		}
+		content = b.Bytes()
+	}
+	if o.index == nil {
+		o.index = make(map[string]*fileOutput)
+	}
+	f := o.index[filename]
+	if f == nil {
+		f = &fileOutput{path: astio.SourceFilename(pkg, file), root: root, filename: filename, content: content, changes: changes, config: c}
+		o.index[filename] = f
+		o.files = append(o.files, f)
+		return false, nil
+	}
+	if f.conflict || bytes.Equal(f.content, content) {
+		return false, nil
+	}
+	log.Printf("%s: NOTE: the rewrites conflict between %s and %s, the file is left as it is", filename, f.config, c)
+	f.conflict = true
+	return true, nil
+}
+
+// write writes the rewritten files except the conflicted ones.
+// It refuses to write a file outside the module root, such as the build cache,
+// and returns the number of the refused files.
+func (o *outputs) write(dryRun bool) (int, error) {
+	var refused int
+	for _, f := range o.files {
+		if f.content == nil {
+			log.Printf("--- no change in %s", f.filename)
+			continue
+		}
+		if f.conflict {
+			continue
+		}
+		if !isWithin(f.root, f.path) {
+			log.Printf("%s: NOTE: the file is outside the module root %s, left as it is", f.filename, f.root)
+			refused++
+			continue
+		}
+		if dryRun {
+			continue
+		}
+		log.Printf("--- writing %d change(s) to %s", f.changes, f.filename)
+		if err := ioutil.WriteFile(f.path, f.content, 0644); err != nil {
+			return refused, fmt.Errorf("could not write the file: %w", err)
+		}
+	}
+	return refused, nil
+}
+
+// isWithin returns true if the path is in the root directory.
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -1,6 +1,7 @@
 package rewrite
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
@@ -12,31 +13,77 @@ import (
 	"golang.org/x/tools/go/packages"
 )
 
-type toPkgErrors struct{}
+type toPkgErrors struct {
+	sentinels *sentinelPackage
+	customs   customPackages
+	filter    *callFilter
+}
 
-func (t *toPkgErrors) Transform(pkg *packages.Package, file *ast.File) (int, error) {
+func (t *toPkgErrors) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
 	var v toPkgErrorsVisitor
-	if err := astio.Inspect(pkg, file, &v); err != nil {
-		return 0, fmt.Errorf("could not inspect the file: %w", err)
+	v.sentinels = t.sentinels
+	v.customs = t.customs
+	v.filter = t.filter
+	// compute the ignored calls once, before the passes replace any node of the file
+	ignored := astio.IgnoredCalls(pkg, file)
+	var m int
+	if t.filter.selected(xerrorsImportPath) {
+		m += migrateXerrorsFormatters(pkg, file, &v.reporter)
+		m += migrateXerrorsFrames(pkg, file, &v.reporter)
+	}
+	m += addDelegateMethods(pkg, file, "Unwrap", "Cause", &v.reporter)
+	if t.filter.selected(jujuErrorsImportPath) {
+		m += v.replaceJujuConstErrors(pkg, file, ignored, t.filter, &v.reporter)
+	}
+	if t.filter.selected(erisImportPath) {
+		m += replaceErisToString(pkg, file, ignored, t.filter)
+	}
+	reportSourceTypes(pkg, file, t.filter, &v.reporter)
+	t.filter.keepUnselectedImports(file, t.customs, pkgErrorsImportPath, &v.reporter)
+	if err := astio.InspectWithIgnoredCalls(pkg, file, ignored, &v); err != nil {
+		return 0, nil, fmt.Errorf("could not inspect the file: %w", err)
 	}
-	if v.needImport == 0 {
-		return 0, nil
+	r := normalizeErrorf(pkg, file, ignored, t.filter, pkgErrorsImportPath, "errors", "errors")
+	c := rewriteChainWalks(pkg, file, t.filter, PkgErrors, "errors")
+	if v.needImport == 0 && r.changes == 0 && m == 0 && c == 0 && len(v.remainingCalls) == 0 && len(v.remainingRefs) == 0 && len(v.remainingMethods) == 0 {
+		return 0, v.diagnostics, nil
 	}
-	n := t.replaceImports(pkg, file)
-	return v.needImport + n, nil
+	n := v.addSentinelImport(pkg, file)
+	n += t.replaceImports(pkg, file, v.needImport+c, v.remainingImportPaths())
+	n += keepRemainingCalls(pkg, file, &v.reporter)
+	return v.needImport + r.changes + m + c + n, v.diagnostics, nil
 }
 
-func (*toPkgErrors) replaceImports(pkg *packages.Package, file *ast.File) int {
+func (t *toPkgErrors) replaceImports(pkg *packages.Package, file *ast.File, needImport int, keepImports map[string]bool) int {
 	var n int
-	if astutil.AddImport(pkg.Fset, file, pkgErrorsImportPath) {
-		n++
-		log.Printf("%s: + import %s", astio.Filename(pkg, file), pkgErrorsImportPath)
+	if needImport > 0 {
+		if astutil.AddImport(pkg.Fset, file, pkgErrorsImportPath) {
+			n++
+			log.Printf("%s: + import %s", astio.Filename(pkg, file), pkgErrorsImportPath)
+		}
 	}
-	if astutil.DeleteImport(pkg.Fset, file, xerrorsImportPath) {
+	if !keepImports[xerrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, xerrorsImportPath) {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), xerrorsImportPath)
 	}
-	if astutil.DeleteImport(pkg.Fset, file, "errors") {
+	if !keepImports[cockroachErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, cockroachErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), cockroachErrorsImportPath)
+	}
+	if !keepImports[jujuErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, jujuErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), jujuErrorsImportPath)
+	}
+	if !keepImports[githubGoErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, githubGoErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), githubGoErrorsImportPath)
+	}
+	if !keepImports[erisImportPath] && astutil.DeleteImport(pkg.Fset, file, erisImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), erisImportPath)
+	}
+	n += t.customs.deleteImports(pkg, file, keepImports)
+	if !keepImports["errors"] && astutil.DeleteImport(pkg.Fset, file, "errors") {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), "errors")
 	}
@@ -51,67 +98,143 @@ func (*toPkgErrors) replaceImports(pkg *packages.Package, file *ast.File) int {
 }
 
 type toPkgErrorsVisitor struct {
+	reporter
+	jujuErrors
+	customs    customPackages
+	filter     *callFilter
 	needImport int
 }
 
 func (v *toPkgErrorsVisitor) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	if skipCall(v.filter, call, &v.reporter) {
+		return nil
+	}
+	var err error
 	switch call.PackagePath() {
 	case xerrorsImportPath:
-		return v.xerrorsFunctionCall(call)
+		err = v.xerrorsFunctionCall(call)
 	case "errors":
-		return v.goErrorsFunctionCall(call)
+		err = v.goErrorsFunctionCall(call)
 	case "fmt":
-		return v.goFmtFunctionCall(call)
+		err = v.goFmtFunctionCall(call)
+	case cockroachErrorsImportPath:
+		err = v.cockroachErrorsFunctionCall(call)
+	case jujuErrorsImportPath:
+		err = v.jujuErrorsFunctionCall(call, v.pkgErrorsFunctionCall)
+	case githubGoErrorsImportPath:
+		err = replaceRenamedFunctionCall(call, renameGitHubGoErrorsFunction, v.pkgErrorsFunctionCall)
+	case erisImportPath:
+		err = replaceRenamedFunctionCall(call, renameErisFunction, v.pkgErrorsFunctionCall)
+	default:
+		if v.customs.find(call.PackagePath()) != nil {
+			err = replaceRenamedFunctionCall(call, v.customs.renameFunction, v.pkgErrorsFunctionCall)
+		}
+	}
+	if err != nil {
+		v.report(call, err)
 	}
 	return nil
 }
 
 func (v *toPkgErrorsVisitor) goErrorsFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
-	case "New", "Unwrap", "As", "Is":
+	case "New":
+		if !replaceNewSprintfWithErrorf(call, "errors") {
+			replacePackageFunctionCall(call, "errors", "")
+		}
+		v.needImport++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "errors", "")
 		v.needImport++
 		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "errors"
-	v.needImport++
-	return nil
+	return errUnsupportedFunction
 }
 
 func (v *toPkgErrorsVisitor) goFmtFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
 	case "Errorf":
+		if err := replaceErrorfWithPkgErrors(call); err != nil {
+			return err
+		}
 		v.needImport++
-		return replaceErrorfWithPkgErrors(call)
+		return nil
 	}
 	return nil
 }
 
 func (v *toPkgErrorsVisitor) xerrorsFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
-	case "New", "Unwrap", "As", "Is":
+	case "New":
+		if !replaceNewSprintfWithErrorf(call, "errors") {
+			replacePackageFunctionCall(call, "errors", "")
+		}
+		v.needImport++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "errors", "")
 		v.needImport++
 		return nil
 
 	case "Errorf":
+		if err := replaceErrorfWithPkgErrors(call); err != nil {
+			return err
+		}
 		v.needImport++
-		return replaceErrorfWithPkgErrors(call)
+		return nil
+
+	case "Caller":
+		return errors.New("xerrors.Frame could not be rewritten in the file")
+
+	case "Opaque":
+		args := call.Args()
+		if len(args) != 1 {
+			return fmt.Errorf("xerrors.Opaque expects 1 argument but has %d arguments", len(args))
+		}
+		// %v does not wrap the error
+		call.SetArgs([]ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%v"`},
+			args[0],
+		})
+		replacePackageFunctionCall(call, "errors", "Errorf")
+		v.needImport++
+		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "errors"
-	v.needImport++
-	return nil
+	return errUnsupportedFunction
+}
+
+// pkgErrorsFunctionCall rewrites the function call which has been renamed to the function of github.com/pkg/errors.
+func (v *toPkgErrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall) error {
+	switch call.FunctionName() {
+	case "New", "Errorf", "Wrap", "Wrapf", "WithStack", "WithMessage", "WithMessagef", "Cause", "Unwrap", "As", "Is":
+		replacePackageFunctionCall(call, "errors", "")
+		v.needImport++
+		return nil
+	}
+
+	return errUnsupportedFunction
+}
+
+func (v *toPkgErrorsVisitor) cockroachErrorsFunctionCall(call astio.PackageFunctionCall) error {
+	renameCockroachErrorsFunction(call)
+	if call.FunctionName() == "Opaque" {
+		return v.xerrorsFunctionCall(call)
+	}
+	return v.pkgErrorsFunctionCall(call)
 }
 
 // replaceErrorfWithPkgErrors rewrites the Errorf function call
 // from fmt.Errorf() or xerrors.Errorf() to pkg/errors.Errorf().
 // If the Errorf wraps an error, it rewrites to pkg/errors.Wrapf().
 func replaceErrorfWithPkgErrors(call astio.PackageFunctionCall) error {
-	args := call.Args()
+	call.SetArgs(inlineSprintf(call.TypesInfo, call.Args()))
+	args, _ := stripErrorCalls(call.TypesInfo, call.Args())
+	call.SetArgs(args)
 	if len(args) < 2 {
 		replacePackageFunctionCall(call, "errors", "Errorf")
 		return nil
@@ -126,11 +249,8 @@ func replaceErrorfWithPkgErrors(call astio.PackageFunctionCall) error {
 
 	// rewrite to pkg/errors specific functions if the format argument matched
 	firstArg, ok := args[0].(*ast.BasicLit)
-	if !ok {
-		return fmt.Errorf("%s: 1st argument of Errorf must be a literal but %T", call.Position, args[0])
-	}
-	if firstArg.Kind != token.STRING {
-		return fmt.Errorf("%s: 1st argument of Errorf must be a string but %s", call.Position, firstArg.Kind)
+	if !ok || firstArg.Kind != token.STRING {
+		return errors.New("1st argument of Errorf must be a string literal")
 	}
 	if firstArg.Value == `"%s: %w"` {
 		call.SetArgs([]ast.Expr{args[2], args[1]})
@@ -20,4 +20,109 @@ func TestToPkgErrors_Transform(t *testing.T) {
 			"testdata/xerrors/common.go",
 			"testdata/pkgerrors/common.go")
 	})
+	t.Run("fmt.Sprintf from go-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/sprintf.go",
+			"testdata/pkgerrors/sprintf_collapsed.go")
+	})
+	t.Run("fmt.Sprintf from xerrors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/xerrors/sprintf.go",
+			"testdata/pkgerrors/sprintf_collapsed.go")
+	})
+	t.Run("Errorf from go-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/errorf.go",
+			"testdata/pkgerrors/errorf_normalized.go")
+	})
+	t.Run("Errorf from xerrors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/xerrors/errorf.go",
+			"testdata/pkgerrors/errorf_normalized.go")
+	})
+	t.Run("unsupported syntax from go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/goerrors/unsupported.go",
+			"testdata/pkgerrors/unsupported_from_goerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("unsupported syntax from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/unsupported.go",
+			"testdata/pkgerrors/unsupported_from_xerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("formatter from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/formatter.go",
+			"testdata/pkgerrors/formatter_from_xerrors.go")
+		// the lost detail, MessageError and the call of xerrors.FormatError() in it
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("frame from xerrors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/xerrors/frame.go",
+			"testdata/pkgerrors/frame_from_xerrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("unwrapper from go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/goerrors/unwrapper.go",
+			"testdata/pkgerrors/unwrapper_from_goerrors.go")
+		// CauseFieldError has the field of Cause
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("chain walk from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/chain.go",
+			"testdata/pkgerrors/chain_from_pkgerrors.go")
+	})
+	t.Run("common syntax from cockroach-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/cockroacherrors/common.go",
+			"testdata/pkgerrors/common.go")
+	})
+	t.Run("specific syntax from cockroach-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/cockroacherrors/specific.go",
+			"testdata/pkgerrors/specific_from_cockroacherrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from juju-errors", func(t *testing.T) {
+		tr := toPkgErrors{sentinels: &sentinelPackage{Path: "github.com/int128/errto/errkind"}}
+		diagnostics := transform(t, &tr,
+			"testdata/jujuerrors/specific.go",
+			"testdata/pkgerrors/specific_from_jujuerrors.go")
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from github go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/githubgoerrors/specific.go",
+			"testdata/pkgerrors/specific_from_githubgoerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from eris", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/eris/specific.go",
+			"testdata/pkgerrors/specific_from_eris.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
 }
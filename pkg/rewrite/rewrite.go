@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"go/ast"
 
 	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/config"
 	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
 )
 
 type Method int
@@ -16,49 +19,247 @@ const (
 	GoErrors
 	Xerrors
 	PkgErrors
+	CockroachErrors
+	Custom // in-house package declared in the config file
+	Auto   // detect the target which minimizes the changes
 )
 
 const (
-	pkgErrorsImportPath = "github.com/pkg/errors"
-	xerrorsImportPath   = "golang.org/x/xerrors"
+	pkgErrorsImportPath       = "github.com/pkg/errors"
+	xerrorsImportPath         = "golang.org/x/xerrors"
+	cockroachErrorsImportPath = "github.com/cockroachdb/errors"
+	jujuErrorsImportPath      = "github.com/juju/errors"
+	githubGoErrorsImportPath  = "github.com/go-errors/errors"
+	erisImportPath            = "github.com/rotisserie/eris"
+	multierrImportPath        = "go.uber.org/multierr"
+	multierrorImportPath      = "github.com/hashicorp/go-multierror"
+	utilerrorsImportPath      = "k8s.io/apimachinery/pkg/util/errors"
 )
 
 type Input struct {
 	PkgNames []string
 	Target   Method
-	DryRun   bool
+	// import paths of the packages to rewrite from.
+	// If empty, all the well-known and in-house packages are rewritten.
+	Sources []string
+	// qualified names of the functions to rewrite, e.g. github.com/pkg/errors.Wrap.
+	// If empty, all the functions are rewritten.
+	OnlyFunctions []string
+	// qualified names of the functions to leave as they are.
+	SkipFunctions []string
+	DryRun        bool
+	Strict        bool // fail if any function call could not be rewritten
+	KeepGoing     bool // skip the packages which have any error instead of failing
+
+	// import path of the package of sentinel errors which replace the error types of github.com/juju/errors.
+	// If empty, errkind package in the module root is used.
+	SentinelPackage string
+
+	// in-house packages of error helpers declared in the config file.
+	// They are rewritten in the same way as the well-known packages.
+	CustomPackages []config.Package
+	// import path of the in-house package to rewrite with, if Target is Custom.
+	CustomTarget string
+
+	// glob patterns of the files to leave as they are, e.g. *_test.go
+	ExcludePatterns []string
+	// rewrite the generated files as well
+	IncludeGenerated bool
+
+	// load the packages in each module of the workspace, i.e. go.work or the directories containing go.mod.
+	// The package names are resolved in each module.
+	AllModules bool
+
+	// build tags and platforms (GOOS/GOARCH) to load the packages.
+	// The packages are loaded for each platform, and each file is rewritten once.
+	Tags      []string
+	Platforms []string
+
+	// inline the unexported helper functions which only return an error of the well-known package
+	InlineHelpers bool
 }
 
 func Do(ctx context.Context, in Input) error {
-	pkgs, err := astio.Load(ctx, in.PkgNames...)
+	configs, err := buildConfigs(in.Tags, in.Platforms)
 	if err != nil {
-		return fmt.Errorf("could not load the packages: %w", err)
+		return err
 	}
-	if len(pkgs) == 0 {
-		return errors.New("no package found")
+	files, err := newFileFilter(in.ExcludePatterns, in.IncludeGenerated)
+	if err != nil {
+		return fmt.Errorf("invalid exclude patterns: %w", err)
+	}
+	dirs, err := moduleDirs(in.AllModules)
+	if err != nil {
+		return err
 	}
-	for _, pkg := range pkgs {
-		for _, file := range pkg.Syntax {
-			t := newTransformer(in.Target)
-			if t == nil {
-				return fmt.Errorf("unknown target method %v", in.Target)
+	load := astio.LoadWithConfig
+	if in.KeepGoing {
+		load = astio.LoadPartially
+	}
+	modules := newModuleOptions(in, files)
+	var broken brokenPackages
+	var out outputs
+	var diagnostics []Diagnostic
+	var conflicts int
+	for _, dir := range dirs {
+		for _, c := range configs {
+			switch {
+			case dir != "" && len(configs) > 1:
+				log.Printf("--- loading the packages in %s for %s", astio.Relative(dir), c)
+			case dir != "":
+				log.Printf("--- loading the packages in %s", astio.Relative(dir))
+			case len(configs) > 1:
+				log.Printf("--- loading the packages for %s", c)
 			}
-			n, err := t.Transform(pkg, file)
+			pkgs, err := load(ctx, c, dir, in.PkgNames...)
 			if err != nil {
-				return fmt.Errorf("could not rewrite the file: %w", err)
+				return fmt.Errorf("could not load the packages: %w", err)
+			}
+			if len(pkgs) == 0 {
+				if dir != "" {
+					log.Printf("NOTE: no package found in %s", astio.Relative(dir))
+					continue
+				}
+				return errors.New("no package found")
 			}
-			if n == 0 {
-				log.Printf("--- no change in %s", astio.Filename(pkg, file))
-				continue
+			pkgs = broken.filter(pkgs)
+			selected := selectVariants(pkgs)
+			if err := modules.prepare(pkgs, selected); err != nil {
+				return err
 			}
-			if !in.DryRun {
-				p := astio.Position(pkg, file)
-				log.Printf("--- writing %d change(s) to %s", n, p.Filename)
-				if err := astio.Write(pkg, file); err != nil {
-					return fmt.Errorf("could not write the file: %w", err)
+			for _, pkg := range pkgs {
+				if !hasSelectedFile(pkg, selected) {
+					continue
 				}
+				m := modules.of(pkg)
+				skip := func(file *ast.File) bool { return !selected[file] || files.skip(pkg, file) }
+				var inlined map[*ast.File]int
+				if in.InlineHelpers {
+					var d []Diagnostic
+					inlined, d = inlineHelpers(pkg, m.opt.customs, m.opt.filter, skip)
+					diagnostics = append(diagnostics, d...)
+				}
+				for _, file := range pkg.Syntax {
+					if skip(file) {
+						continue
+					}
+					t := newTransformer(m.target, m.opt)
+					if t == nil {
+						return fmt.Errorf("unknown target method %v", m.target)
+					}
+					n, d, err := t.Transform(pkg, file)
+					if err != nil {
+						return fmt.Errorf("could not rewrite the file: %w", err)
+					}
+					n += inlined[file]
+					diagnostics = append(diagnostics, d...)
+					conflict, err := out.add(pkg, file, n, c, m.root)
+					if err != nil {
+						return err
+					}
+					if conflict {
+						conflicts++
+					}
+				}
+			}
+		}
+	}
+	if len(modules.modules) == 0 && len(broken.ids) == 0 {
+		return errors.New("no package found")
+	}
+	refused, err := out.write(in.DryRun)
+	if err != nil {
+		return err
+	}
+	files.printSummary()
+	broken.printSummary()
+	for _, m := range modules.modules {
+		if m.sentinels != nil && m.sentinels.used && !in.DryRun {
+			if err := m.sentinels.write(); err != nil {
+				return fmt.Errorf("could not write the sentinel package: %w", err)
+			}
+		}
+	}
+	diagnostics = uniqueDiagnostics(diagnostics)
+	if len(diagnostics) > 0 {
+		log.Printf("--- %d function call(s) need to be rewritten manually", len(diagnostics))
+		if in.Strict {
+			for _, d := range diagnostics {
+				log.Printf("%s", d)
 			}
+			return fmt.Errorf("could not rewrite %d function call(s)", len(diagnostics))
 		}
 	}
+	if conflicts > 0 {
+		log.Printf("--- %d file(s) are left as they are because of the conflicting rewrites", conflicts)
+	}
+	if refused > 0 {
+		log.Printf("--- %d file(s) outside the module root are left as they are", refused)
+	}
+	if in.Strict && conflicts+refused > 0 {
+		return fmt.Errorf("could not rewrite %d file(s)", conflicts+refused)
+	}
+	if in.Strict && len(broken.ids) > 0 {
+		return fmt.Errorf("could not load %d package(s)", len(broken.ids))
+	}
 	return nil
 }
+
+func newTransformerOption(in Input, m *astio.Module, sentinels *sentinelPackage) (transformerOption, error) {
+	opt := transformerOption{
+		sentinels:  sentinels,
+		joinErrors: m != nil && m.GoVersionAtLeast(1, 20),
+		customs:    customPackages(in.CustomPackages),
+	}
+	var err error
+	opt.filter, err = newCallFilter(in.Sources, in.OnlyFunctions, in.SkipFunctions)
+	if err != nil {
+		return opt, fmt.Errorf("invalid filter: %w", err)
+	}
+	if in.Target == Custom {
+		opt.customTarget = opt.customs.find(in.CustomTarget)
+		if opt.customTarget == nil {
+			return opt, fmt.Errorf("package %s is not declared in the config file", in.CustomTarget)
+		}
+	}
+	return opt, nil
+}
+
+// buildConfigs returns the combinations of the build tags and platforms.
+// It returns the host platform if no platform is given.
+func buildConfigs(tags, platforms []string) ([]astio.BuildConfig, error) {
+	if len(platforms) == 0 {
+		return []astio.BuildConfig{{Tags: tags}}, nil
+	}
+	var configs []astio.BuildConfig
+	for _, platform := range platforms {
+		if _, _, err := astio.ParsePlatform(platform); err != nil {
+			return nil, err
+		}
+		configs = append(configs, astio.BuildConfig{Tags: tags, Platform: platform})
+	}
+	return configs, nil
+}
+
+func hasSelectedFile(pkg *packages.Package, selected map[*ast.File]bool) bool {
+	for _, file := range pkg.Syntax {
+		if selected[file] {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueDiagnostics removes the duplicated diagnostics of the build configurations and the test variants.
+func uniqueDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	seen := make(map[Diagnostic]bool)
+	var unique []Diagnostic
+	for _, d := range diagnostics {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		unique = append(unique, d)
+	}
+	return unique
+}
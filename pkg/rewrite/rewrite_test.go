@@ -0,0 +1,53 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/errto/pkg/astio"
+)
+
+func TestBuildConfigs(t *testing.T) {
+	t.Run("host", func(t *testing.T) {
+		got, err := buildConfigs([]string{"integration"}, nil)
+		if err != nil {
+			t.Fatalf("buildConfigs error: %s", err)
+		}
+		want := []astio.BuildConfig{{Tags: []string{"integration"}}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("platforms", func(t *testing.T) {
+		got, err := buildConfigs(nil, []string{"linux/amd64", "windows/amd64"})
+		if err != nil {
+			t.Fatalf("buildConfigs error: %s", err)
+		}
+		want := []astio.BuildConfig{{Platform: "linux/amd64"}, {Platform: "windows/amd64"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("invalid platform", func(t *testing.T) {
+		if _, err := buildConfigs(nil, []string{"linux"}); err == nil {
+			t.Errorf("buildConfigs wants an error")
+		}
+	})
+}
+
+func TestIsWithin(t *testing.T) {
+	for _, c := range []struct {
+		path string
+		want bool
+	}{
+		{"/src/module/main.go", true},
+		{"/src/module/pkg/foo/foo.go", true},
+		{"/src/module2/main.go", false},
+		{"/src/main.go", false},
+		{"/home/user/.cache/go-build/00/0000-d", false},
+	} {
+		if got := isWithin("/src/module", c.path); got != c.want {
+			t.Errorf("isWithin(%s) wants %v but was %v", c.path, c.want, got)
+		}
+	}
+}
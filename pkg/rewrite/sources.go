@@ -0,0 +1,250 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/config"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/packages"
+)
+
+var methodNames = map[Method]string{
+	GoErrors:        "go-errors",
+	Xerrors:         "xerrors",
+	PkgErrors:       "pkg-errors",
+	CockroachErrors: "cockroach-errors",
+	Custom:          "custom",
+	Auto:            "auto",
+}
+
+func (m Method) String() string {
+	if name, ok := methodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// ParseMethod returns the method of the name, e.g. go-errors.
+func ParseMethod(name string) (Method, error) {
+	for m, n := range methodNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown method %s", name)
+}
+
+// SourceImportPaths returns the import paths of the source.
+// The source is either a name of the method, a well-known import path or an import path of the in-house package,
+// e.g. go-errors is errors and fmt of the standard library, and custom is all the in-house packages.
+// It returns an error if the source is unknown, such as a typo of the method.
+func SourceImportPaths(source string, customs []config.Package) ([]string, error) {
+	m, err := ParseMethod(source)
+	if err == nil {
+		switch m {
+		case GoErrors:
+			return []string{"errors", "fmt"}, nil
+		case Xerrors:
+			return []string{xerrorsImportPath}, nil
+		case PkgErrors:
+			return []string{pkgErrorsImportPath}, nil
+		case CockroachErrors:
+			return []string{cockroachErrorsImportPath}, nil
+		case Custom:
+			if len(customs) == 0 {
+				return nil, fmt.Errorf("no package is declared in %s", config.Filename)
+			}
+			var paths []string
+			for _, c := range customs {
+				paths = append(paths, c.Path)
+			}
+			return paths, nil
+		}
+		return nil, fmt.Errorf("%s cannot be a source", source)
+	}
+	if errorsPackagePaths[source] || isMultiErrorsPath(source) || customPackages(customs).find(source) != nil {
+		return []string{source}, nil
+	}
+	return nil, fmt.Errorf("%s is neither a method, a well-known import path nor a package declared in %s", source, config.Filename)
+}
+
+// callFilter selects the function calls to rewrite.
+// A nil filter selects all the function calls of the well-known and in-house packages.
+type callFilter struct {
+	sources map[string]bool // import paths of the packages to rewrite from, or nil for all
+	only    map[string]bool // qualified names of the functions to rewrite, or nil for all
+	skip    map[string]bool // qualified names of the functions to leave as they are
+}
+
+// newCallFilter returns a filter of the import paths and the qualified function names,
+// e.g. github.com/pkg/errors.WithStack.
+// It returns nil if nothing is given.
+func newCallFilter(sources, only, skip []string) (*callFilter, error) {
+	if len(sources) == 0 && len(only) == 0 && len(skip) == 0 {
+		return nil, nil
+	}
+	var f callFilter
+	if len(sources) > 0 {
+		f.sources = make(map[string]bool)
+		for _, path := range sources {
+			f.sources[path] = true
+		}
+	}
+	var err error
+	if f.only, err = functionSet(only); err != nil {
+		return nil, err
+	}
+	if f.skip, err = functionSet(skip); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func functionSet(names []string) (map[string]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	set := make(map[string]bool)
+	for _, name := range names {
+		if _, _, ok := splitQualifiedName(name); !ok {
+			return nil, fmt.Errorf("%s is not a qualified function name, e.g. github.com/pkg/errors.Wrap", name)
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+// splitQualifiedName splits the qualified function name into the import path and the function name.
+func splitQualifiedName(name string) (string, string, bool) {
+	i := strings.LastIndex(name, ".")
+	if i < 1 || strings.Contains(name[i:], "/") || !token.IsIdentifier(name[i+1:]) {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// selected returns true if the package of the import path should be rewritten.
+// If the functions are given by only, the package must have any of them.
+func (f *callFilter) selected(path string) bool {
+	if f == nil {
+		return true
+	}
+	if f.sources != nil && !f.sources[path] {
+		return false
+	}
+	if f.only == nil {
+		return true
+	}
+	for name := range f.only {
+		if p, _, _ := splitQualifiedName(name); p == path || isTypeOf(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTypeOf returns true if the qualified name is a type of the package,
+// e.g. github.com/hashicorp/go-multierror.Error for the method github.com/hashicorp/go-multierror.Error.ErrorOrNil.
+func isTypeOf(name, path string) bool {
+	return strings.HasPrefix(name, path+".") && token.IsIdentifier(name[len(path)+1:])
+}
+
+// selectedFunction returns true if the function of the package should be rewritten.
+// The name of a method or field is qualified by the type, e.g. Error.ErrorOrNil.
+func (f *callFilter) selectedFunction(path, name string) bool {
+	if !f.selected(path) {
+		return false
+	}
+	if f == nil {
+		return true
+	}
+	qualifiedName := path + "." + name
+	if f.only != nil && !f.only[qualifiedName] {
+		return false
+	}
+	return !f.skip[qualifiedName]
+}
+
+// skipCall returns true if the function call is not selected.
+// The function call of an unselected package is left as it is.
+// The function call of a selected package is left as it is, and the import is kept for it.
+func skipCall(f *callFilter, call astio.PackageFunctionCall, r *reporter) bool {
+	path := call.PackagePath()
+	if !f.selected(path) {
+		return true
+	}
+	if !f.selectedFunction(path, call.FunctionName()) {
+		r.skip(call)
+		return true
+	}
+	return false
+}
+
+// keepUnselectedImports keeps the imports of the well-known and in-house packages which are not selected,
+// except the target.
+// The code of them is left as it is, and therefore the imports must not be deleted.
+// If the package name conflicts with the target, keepRemainingCalls aliases the import.
+func (f *callFilter) keepUnselectedImports(file *ast.File, customs customPackages, target string, r *reporter) {
+	if f == nil {
+		return
+	}
+	for _, spec := range file.Imports {
+		path := importPath(spec)
+		if path == target || f.selected(path) {
+			continue
+		}
+		if (errorsPackagePaths[path] && path != "fmt") || isMultiErrorsPath(path) || customs.find(path) != nil {
+			r.keepImport(path)
+			if r.unselectedImports == nil {
+				r.unselectedImports = make(map[string]bool)
+			}
+			r.unselectedImports[path] = true
+		}
+	}
+}
+
+func isMultiErrorsPath(path string) bool {
+	switch path {
+	case multierrImportPath, multierrorImportPath, utilerrorsImportPath:
+		return true
+	}
+	return false
+}
+
+// aliasUnselectedImports aliases the imports of the unselected packages
+// if the package name conflicts with another import, e.g. the target.
+// All the references to the package are renamed, because nothing of it has been rewritten.
+// It returns the number of changes.
+func aliasUnselectedImports(pkg *packages.Package, file *ast.File, r *reporter) int {
+	var paths []string
+	for path := range r.unselectedImports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var n int
+	for _, path := range paths {
+		spec := conflictingImport(pkg, file, path)
+		if spec == nil {
+			continue
+		}
+		alias := importAlias(path)
+		spec.Name = ast.NewIdent(alias)
+		ast.Inspect(file, func(node ast.Node) bool {
+			if x, ok := node.(*ast.Ident); ok {
+				if pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); ok && pkgName.Imported().Path() == path {
+					x.Name = alias
+				}
+			}
+			return true
+		})
+		log.Printf("%s: import %s as %s", astio.Filename(pkg, file), path, alias)
+		n++
+	}
+	return n
+}
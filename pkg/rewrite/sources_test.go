@@ -0,0 +1,85 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/int128/errto/pkg/config"
+)
+
+func TestCallFilter(t *testing.T) {
+	t.Run("only", func(t *testing.T) {
+		f, err := newCallFilter(nil, []string{"github.com/pkg/errors.Wrap", "github.com/pkg/errors.Wrapf"}, nil)
+		if err != nil {
+			t.Fatalf("newCallFilter error: %s", err)
+		}
+		for _, c := range []struct {
+			path, name string
+			want       bool
+		}{
+			{pkgErrorsImportPath, "Wrap", true},
+			{pkgErrorsImportPath, "Wrapf", true},
+			{pkgErrorsImportPath, "WithStack", false},
+			{xerrorsImportPath, "Errorf", false},
+		} {
+			if got := f.selectedFunction(c.path, c.name); got != c.want {
+				t.Errorf("selectedFunction(%s, %s) wants %v but was %v", c.path, c.name, c.want, got)
+			}
+		}
+		if f.selected(xerrorsImportPath) {
+			t.Errorf("selected(%s) wants false", xerrorsImportPath)
+		}
+	})
+	t.Run("only method", func(t *testing.T) {
+		f, err := newCallFilter(nil, []string{"github.com/hashicorp/go-multierror.Error.ErrorOrNil"}, nil)
+		if err != nil {
+			t.Fatalf("newCallFilter error: %s", err)
+		}
+		if !f.selectedFunction(multierrorImportPath, "Error.ErrorOrNil") {
+			t.Errorf("selectedFunction(%s, Error.ErrorOrNil) wants true", multierrorImportPath)
+		}
+		if f.selectedFunction(multierrorImportPath, "Append") {
+			t.Errorf("selectedFunction(%s, Append) wants false", multierrorImportPath)
+		}
+	})
+	t.Run("skip", func(t *testing.T) {
+		f, err := newCallFilter([]string{pkgErrorsImportPath, "errors"}, nil, []string{"errors.Is", "errors.As"})
+		if err != nil {
+			t.Fatalf("newCallFilter error: %s", err)
+		}
+		for _, c := range []struct {
+			path, name string
+			want       bool
+		}{
+			{pkgErrorsImportPath, "Is", true},
+			{"errors", "New", true},
+			{"errors", "Is", false},
+			{"errors", "As", false},
+			{xerrorsImportPath, "New", false},
+		} {
+			if got := f.selectedFunction(c.path, c.name); got != c.want {
+				t.Errorf("selectedFunction(%s, %s) wants %v but was %v", c.path, c.name, c.want, got)
+			}
+		}
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		for _, name := range []string{"Wrap", "github.com/pkg/errors", "github.com/pkg/errors."} {
+			if _, err := newCallFilter(nil, nil, []string{name}); err == nil {
+				t.Errorf("newCallFilter(%s) wants an error", name)
+			}
+		}
+	})
+}
+
+func TestSourceImportPaths(t *testing.T) {
+	customs := []config.Package{{Path: "example.com/errors"}}
+	for _, source := range []string{"pkg-errors", "custom", multierrImportPath, jujuErrorsImportPath, "example.com/errors"} {
+		if _, err := SourceImportPaths(source, customs); err != nil {
+			t.Errorf("SourceImportPaths(%s) wants no error but was %s", source, err)
+		}
+	}
+	for _, source := range []string{"pkg-error", "auto", "example.com/unknown"} {
+		if _, err := SourceImportPaths(source, customs); err == nil {
+			t.Errorf("SourceImportPaths(%s) wants an error", source)
+		}
+	}
+}
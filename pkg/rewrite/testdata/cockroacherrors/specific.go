@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/cockroachdb/errors"
+)
+
+func specificSyntax(x int, err error) {
+	// format an error
+	errors.Newf("FORMAT %d", x)
+
+	// unwrap an error
+	errors.UnwrapOnce(err)
+
+	// get the root cause of an error
+	errors.UnwrapAll(err)
+	errors.Cause(err)
+
+	// hide the chain of an error
+	errors.Opaque(err)
+
+	// add a hint to an error
+	errors.WithHint(err, "HINT")
+}
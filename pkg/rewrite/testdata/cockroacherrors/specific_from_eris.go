@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/rotisserie/eris"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	errors.Wrap(err, "MESSAGE")
+	errors.Wrapf(err, "FORMAT %d", x)
+
+	// get the cause of an error
+	errors.UnwrapAll(err)
+	errors.Unwrap(err)
+
+	// print an error
+	println(err.Error())
+	// TODO(errto): rewrite eris.ToString() manually: the stack trace is not available in the target
+	println(eris.ToString(err, true))
+}
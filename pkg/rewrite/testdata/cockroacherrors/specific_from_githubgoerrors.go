@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/cockroachdb/errors"
+	goerrorserrors "github.com/go-errors/errors"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// wrap an error with the stack trace
+	errors.WithStack(err)
+	errors.WithStack(err)
+	errors.Wrap(err, "MESSAGE")
+
+	// wrap an error with the stack trace of the caller
+	// TODO(errto): rewrite goerrorserrors.Wrap() manually: skip argument is not supported
+	goerrorserrors.Wrap(err, 1)
+	// TODO(errto): rewrite goerrorserrors.WrapPrefix() manually: skip argument is not supported
+	goerrorserrors.WrapPrefix(err, "MESSAGE", 1)
+
+	// test an error
+	errors.Is(err, err)
+
+	// print the stack trace
+	// TODO(errto): rewrite goerrorserrors.Error manually: the type is not available in the target
+	if e, ok := err.(*goerrorserrors.Error); ok {
+		// TODO(errto): rewrite e.ErrorStack() manually: the stack trace is not available in the target
+		println(e.ErrorStack())
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"github.com/int128/errto/pkg/rewrite/testdata/custom/errs"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func commonSyntax(x int, y string, err error) {
+	// create an error
+	errs.New("MESSAGE")
+
+	// format an error
+	errs.Newf("FORMAT %d", x)
+	errs.Newf("FORMAT %d, %s", x, y)
+
+	// wrap an error
+	errs.Wrapf(err, "FORMAT")
+	errs.Wrapf(err, "FORMAT %d", x)
+	errs.Wrapf(err, "FORMAT %d, %s", x, y)
+
+	// unwrap an error
+	errs.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errs.As(err, &targetErr)
+
+	// test an error
+	errs.Is(err, &targetErr)
+
+	// wrap an error without format
+	errs.Wrap(err, "MESSAGE")
+
+	// wrap an error with the stack trace
+	fmt.Errorf("%w", err)
+
+	// wrap an error with a message
+	fmt.Errorf("%s: %s", "MESSAGE", err)
+
+	// wrap an error with a message
+	fmt.Errorf("FORMAT: %s", err)
+	fmt.Errorf("FORMAT %d: %s", x, err)
+	fmt.Errorf("FORMAT %d, %s: %s", x, y, err)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/int128/errto/pkg/rewrite/testdata/custom/errs"
+	"github.com/pkg/errors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func commonSyntax(x int, y string, err error) {
+	// create an error
+	errs.New("MESSAGE")
+
+	// format an error
+	errs.Newf("FORMAT %d", x)
+	errs.Newf("FORMAT %d, %s", x, y)
+
+	// wrap an error
+	errs.Wrapf(err, "FORMAT")
+	errs.Wrapf(err, "FORMAT %d", x)
+	errs.Wrapf(err, "FORMAT %d, %s", x, y)
+
+	// unwrap an error
+	errs.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errs.As(err, &targetErr)
+
+	// test an error
+	errs.Is(err, &targetErr)
+
+	// wrap an error without format
+	errs.Wrap(err, "MESSAGE")
+
+	// wrap an error with the stack trace
+	// TODO(errto): rewrite errors.WithStack() manually: no function of github.com/int128/errto/pkg/rewrite/testdata/custom/errs is mapped to with-stack
+	errors.WithStack(err)
+
+	// wrap an error with a message
+	// TODO(errto): rewrite errors.WithMessage() manually: no function of github.com/int128/errto/pkg/rewrite/testdata/custom/errs is mapped to with-message
+	errors.WithMessage(err, "MESSAGE")
+
+	// wrap an error with a message
+	// TODO(errto): rewrite errors.WithMessagef() manually: no equivalent function in the target
+	errors.WithMessagef(err, "FORMAT")
+	// TODO(errto): rewrite errors.WithMessagef() manually: no equivalent function in the target
+	errors.WithMessagef(err, "FORMAT %d", x)
+	// TODO(errto): rewrite errors.WithMessagef() manually: no equivalent function in the target
+	errors.WithMessagef(err, "FORMAT %d, %s", x, y)
+}
@@ -0,0 +1,40 @@
+// Package errs is an in-house package of error helpers for the tests.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+func New(message string) error {
+	return errors.New(message)
+}
+
+func Newf(format string, args ...interface{}) error {
+	return fmt.Errorf(format, args...)
+}
+
+func Wrap(err error, message string) error {
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+func Wrapf(err error, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Code returns the status code of the error.
+func Code(err error) int {
+	return 500
+}
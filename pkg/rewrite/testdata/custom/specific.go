@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/int128/errto/pkg/rewrite/testdata/custom/errs"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errs.New("MESSAGE")
+	errs.Newf("FORMAT %d", x)
+
+	// wrap an error
+	errs.Wrap(err, "MESSAGE")
+	errs.Wrapf(err, "FORMAT %d", x)
+
+	// unwrap an error
+	errs.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errs.As(err, &targetErr)
+
+	// test an error
+	errs.Is(err, &targetErr)
+
+	// not declared in the config
+	errs.Code(err)
+}
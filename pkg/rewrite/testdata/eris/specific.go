@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/rotisserie/eris"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	eris.New("MESSAGE")
+	eris.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	eris.Wrap(err, "MESSAGE")
+	eris.Wrapf(err, "FORMAT %d", x)
+
+	// get the cause of an error
+	eris.Cause(err)
+	eris.Unwrap(err)
+
+	// print an error
+	println(eris.ToString(err, false))
+	println(eris.ToString(err, true))
+}
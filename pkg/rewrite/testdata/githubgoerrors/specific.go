@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/go-errors/errors"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// wrap an error with the stack trace
+	errors.New(err)
+	errors.Wrap(err, 0)
+	errors.WrapPrefix(err, "MESSAGE", 0)
+
+	// wrap an error with the stack trace of the caller
+	errors.Wrap(err, 1)
+	errors.WrapPrefix(err, "MESSAGE", 1)
+
+	// test an error
+	errors.Is(err, err)
+
+	// print the stack trace
+	if e, ok := err.(*errors.Error); ok {
+		println(e.ErrorStack())
+	}
+}
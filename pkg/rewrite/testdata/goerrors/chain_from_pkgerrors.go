@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+)
+
+var errNotFound = errors.New("not found")
+
+func cause(err error) error {
+	// walk the chain
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+	return err
+}
+
+func causeOnce(err error) error {
+	if c := errors.Unwrap(err); c != nil {
+		err = c
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+func isNotFoundOrNil(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+func root(err error) error {
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+	return err
+}
+
+func unwrapValue(v interface{}) interface{} {
+	if u, ok := v.(interface{ Unwrap() error }); ok {
+		v = u.Unwrap()
+	}
+	return v
+}
+
+func rootIgnored(err error) error {
+	//errto:ignore
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return err
+}
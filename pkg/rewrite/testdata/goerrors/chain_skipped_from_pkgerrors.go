@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+)
+
+var errNotFound = errors.New("not found")
+
+func cause(err error) error {
+	// walk the chain
+	for err != nil {
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
+
+func causeOnce(err error) error {
+	if c, ok := err.(interface{ Cause() error }); ok {
+		err = c.Cause()
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+func isNotFoundOrNil(err error) bool {
+	for err != nil {
+		if err == errNotFound {
+			return true
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+func root(err error) error {
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+	return err
+}
+
+func unwrapValue(v interface{}) interface{} {
+	if u, ok := v.(interface{ Unwrap() error }); ok {
+		v = u.Unwrap()
+	}
+	return v
+}
+
+func rootIgnored(err error) error {
+	//errto:ignore
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return err
+}
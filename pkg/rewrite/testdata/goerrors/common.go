@@ -16,7 +16,6 @@ func commonSyntax(x int, y string, err error) {
 	errors.New("MESSAGE")
 
 	// format an error
-	fmt.Errorf("FORMAT")
 	fmt.Errorf("FORMAT %d", x)
 	fmt.Errorf("FORMAT %d, %s", x, y)
 
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	fmt.Errorf("MESSAGE")
+	fmt.Errorf("100%% MESSAGE")
+
+	// format an error with the message of an error
+	fmt.Errorf("FORMAT: %s", err.Error())
+	fmt.Errorf("FORMAT %d: %s", x, err.Error())
+}
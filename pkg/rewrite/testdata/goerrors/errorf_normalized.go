@@ -0,0 +1,16 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	errors.New("MESSAGE")
+	errors.New("100% MESSAGE")
+
+	// format an error with the message of an error
+	fmt.Errorf("FORMAT: %s", err)
+	fmt.Errorf("FORMAT %d: %s", x, err)
+}
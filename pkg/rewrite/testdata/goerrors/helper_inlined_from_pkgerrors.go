@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+func withStack(err error) error {
+	return fmt.Errorf("%w", err)
+}
+
+func withMessage(msg string, err error) error {
+	return fmt.Errorf("%s: %s", msg, err)
+}
+
+// annotate is not inlined, because the call is marked by the ignore directive.
+func annotate(err error, msg string) error {
+	return errors.WithMessage(err, msg)	//errto:ignore
+}
+
+// notFound is not a helper, because it has a statement.
+func notFound(name string) error {
+	name = "<" + name + ">"
+	return fmt.Errorf("%s not found", name)
+}
+
+func helperSyntax(x int, err error) error {
+	if err := fmt.Errorf("%s: %w", "MESSAGE", err); err != nil {
+		return err
+	}
+	if x < 0 {
+		return fmt.Errorf("FORMAT %d: %w", x, err)
+	}
+	if x == 0 {
+		return fmt.Errorf("%s: %s", "MESSAGE", err)
+	}
+	if x == 1 {
+		return annotate(err, "MESSAGE")
+	}
+	return fmt.Errorf("%w", err)
+}
+
+func helperReferredAsValue(errs []error) {
+	for _, err := range errs {
+		fmt.Errorf("%w", err)
+	}
+	f := withStack
+	f(nil)
+}
+
+func helperShadowed(errors []error) error {
+	if len(errors) == 0 {
+		return notFound("errors")
+	}
+	return withMessage("MESSAGE", errors[0])
+}
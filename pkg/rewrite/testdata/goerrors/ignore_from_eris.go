@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/rotisserie/eris"
+)
+
+func ignore(err error) {
+	// rewritten
+	println(err.Error())
+
+	// left as they are
+	println(eris.ToString(err, false))	//errto:ignore
+	//errto:ignore ToString
+	println(eris.ToString(err, false))
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"errors"
+	"github.com/int128/errto/errkind"
+	jujuerrors "github.com/juju/errors"
+)
+
+func ignore(err error) bool {
+	// rewritten
+	_ = errors.Is(err, errkind.ErrNotFound)
+
+	// left as they are
+	_ = jujuerrors.Is(err, jujuerrors.NotFound)	//errto:ignore
+	//errto:ignore Is
+	return jujuerrors.Is(err, jujuerrors.AlreadyExists)
+}
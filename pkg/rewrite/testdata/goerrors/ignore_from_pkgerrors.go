@@ -0,0 +1,26 @@
+//errto:ignore-file Cause // the library returns the error with a stack trace
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func ignore(err error) error {
+	// rewritten
+	_ = errors.New("MESSAGE")
+	_ = fmt.Errorf("%s: %w", "MESSAGE", err)
+
+	// left as they are
+	//errto:ignore
+	_ = pkgerrors.New("MESSAGE")
+	_ = pkgerrors.Wrap(err, "MESSAGE")	//errto:ignore
+	_ = pkgerrors.Wrapf(err,
+		"FORMAT %d", 1)	//errto:ignore // a third-party API expects StackTrace()
+	_ = pkgerrors.Cause(err)
+
+	//errto:ignore WithStack,github.com/pkg/errors.Wrap
+	return pkgerrors.Wrap(pkgerrors.WithStack(errors.New("MESSAGE")), "MESSAGE")
+}
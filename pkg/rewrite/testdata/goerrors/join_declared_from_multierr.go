@@ -0,0 +1,14 @@
+package main
+
+import (
+	"go.uber.org/multierr"
+)
+
+func countErrors(err error) int {
+	// TODO(errto): rewrite multierr.Errors() manually: unwrapErrors() is already declared
+	return len(multierr.Errors(err))
+}
+
+func unwrapErrors(err error) []error {
+	return []error{err}
+}
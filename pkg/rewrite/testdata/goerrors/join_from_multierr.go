@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"go.uber.org/multierr"
+)
+
+func appendAll(errs []error) error {
+	var err error
+	for _, e := range errs {
+		err = combineErrors(err, e)
+	}
+	return err
+}
+
+func combine(a, b error) error {
+	return combineErrors(a, b)
+}
+
+func countErrors(err error) int {
+	return len(unwrapErrors(err))
+}
+
+func appendInto(err *error, e error) bool {
+	// TODO(errto): rewrite multierr.AppendInto() manually: no equivalent function in the target
+	return multierr.AppendInto(err, e)
+}
+
+func unwrapErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		errs = append(errs, unwrapErrors(e)...)
+	}
+	return errs
+}
+
+func combineErrors(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	if len(nonNil) == 1 {
+		return nonNil[0]
+	}
+	return errors.Join(nonNil...)
+}
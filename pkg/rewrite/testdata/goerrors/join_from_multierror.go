@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"github.com/hashicorp/go-multierror"
+)
+
+func appendErrors(errs []error) error {
+	var result error
+	for _, e := range errs {
+		// multierror.Append() flattens the errors, but errors.Join() nests them.
+		// The message of the nested errors is the same as the flat ones, and unwrapErrors() flattens them again.
+		result = errors.Join(result, e)
+	}
+	return result
+}
+
+func appendAll(a, b error) error {
+	return errors.Join(a, b)
+}
+
+func countErrors(result error) int {
+	return len(unwrapErrors(result))
+}
+
+func wrappedErrors(err error) []error {
+	// TODO(errto): rewrite multierror.Error manually: the type is not available in errors.Join
+	if merr, ok := err.(*multierror.Error); ok {
+		return unwrapErrors(merr)
+	}
+	return nil
+}
+
+func appendWithFormat(errs []error) error {
+	// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+	var result *multierror.Error
+	for _, e := range errs {
+		result = multierror.Append(result, e)
+	}
+	if result != nil {
+		result.ErrorFormat = func(errs []error) string { return "" }
+	}
+	return result.ErrorOrNil()
+}
+
+// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+func setFormat(result *multierror.Error) {
+	result.ErrorFormat = func(errs []error) string { return "" }
+}
+
+func unwrapErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		errs = append(errs, unwrapErrors(e)...)
+	}
+	return errs
+}
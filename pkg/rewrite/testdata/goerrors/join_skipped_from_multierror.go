@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/hashicorp/go-multierror"
+)
+
+func appendErrors(errs []error) error {
+	// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+	var result *multierror.Error
+	for _, e := range errs {
+		// multierror.Append() flattens the errors, but errors.Join() nests them.
+		// The message of the nested errors is the same as the flat ones, and unwrapErrors() flattens them again.
+		result = multierror.Append(result, e)
+	}
+	return result.ErrorOrNil()
+}
+
+func appendAll(a, b error) error {
+	return multierror.Append(a, b).ErrorOrNil()
+}
+
+// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+func countErrors(result *multierror.Error) int {
+	return len(result.Errors)
+}
+
+func wrappedErrors(err error) []error {
+	// TODO(errto): rewrite multierror.Error manually: the type is not available in errors.Join
+	if merr, ok := err.(*multierror.Error); ok {
+		return merr.WrappedErrors()
+	}
+	return nil
+}
+
+func appendWithFormat(errs []error) error {
+	// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+	var result *multierror.Error
+	for _, e := range errs {
+		result = multierror.Append(result, e)
+	}
+	if result != nil {
+		result.ErrorFormat = func(errs []error) string { return "" }
+	}
+	return result.ErrorOrNil()
+}
+
+// TODO(errto): rewrite multierror.Error manually: result uses the field or method which is left as it is
+func setFormat(result *multierror.Error) {
+	result.ErrorFormat = func(errs []error) string { return "" }
+}
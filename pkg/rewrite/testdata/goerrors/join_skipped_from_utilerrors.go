@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func aggregate(errs []error) error {
+	return errors.Join(errs...)
+}
+
+func flatten(agg error) error {
+	return errors.Join(unwrapErrors(agg)...)
+}
+
+func reduce(errs []error) error {
+	return utilerrors.Reduce(errors.Join(errs...))
+}
+
+func countErrors(err error) int {
+	// TODO(errto): rewrite utilerrors.Aggregate manually: the type is not available in errors.Join
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		return len(agg.Errors())
+	}
+	return 1
+}
+
+func filterOut(err error) error {
+	// TODO(errto): rewrite utilerrors.FilterOut() manually: no equivalent function in the target
+	return utilerrors.FilterOut(err, func(error) bool { return false })
+}
+
+func unwrapErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		errs = append(errs, unwrapErrors(e)...)
+	}
+	return errs
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"go.uber.org/multierr"
+)
+
+func appendAll(errs []error) error {
+	var err error
+	for _, e := range errs {
+		// TODO(errto): rewrite multierr.Append() manually: errors.Join is available in Go 1.20 or later
+		err = multierr.Append(err, e)
+	}
+	return err
+}
+
+func combine(a, b error) error {
+	// TODO(errto): rewrite multierr.Combine() manually: errors.Join is available in Go 1.20 or later
+	return multierr.Combine(a, b)
+}
+
+func countErrors(err error) int {
+	// TODO(errto): rewrite multierr.Errors() manually: errors.Join is available in Go 1.20 or later
+	return len(multierr.Errors(err))
+}
+
+func appendInto(err *error, e error) bool {
+	// TODO(errto): rewrite multierr.AppendInto() manually: errors.Join is available in Go 1.20 or later
+	return multierr.AppendInto(err, e)
+}
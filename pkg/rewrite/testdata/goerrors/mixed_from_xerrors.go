@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+)
+
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+func mixedSyntax(err error) {
+	// rewritten
+	errors.New("MESSAGE")
+	fmt.Errorf("FORMAT: %w", err)
+	errors.New("MESSAGE")
+
+	// left as they are
+	pkgerrors.New("MESSAGE")
+	pkgerrors.Wrap(err, "MESSAGE")
+	fmt.Errorf("MESSAGE")
+}
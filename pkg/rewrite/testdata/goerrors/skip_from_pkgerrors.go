@@ -0,0 +1,16 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func skip(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%s: %w", "MESSAGE", err)
+	}
+	return pkgerrors.WithStack(err)
+}
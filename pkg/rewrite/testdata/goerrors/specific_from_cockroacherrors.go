@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	cockroachdberrors "github.com/cockroachdb/errors"
+)
+
+func specificSyntax(x int, err error) {
+	// format an error
+	fmt.Errorf("FORMAT %d", x)
+
+	// unwrap an error
+	errors.Unwrap(err)
+
+	// get the root cause of an error
+	errors.Unwrap(err)
+	errors.Unwrap(err)
+
+	// hide the chain of an error
+	fmt.Errorf("%v", err)
+
+	// add a hint to an error
+	// TODO(errto): rewrite cockroachdberrors.WithHint() manually: no equivalent function in the target
+	cockroachdberrors.WithHint(err, "HINT")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/int128/errto/pkg/rewrite/testdata/custom/errs"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	fmt.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	fmt.Errorf("%s: %w", "MESSAGE", err)
+	fmt.Errorf("FORMAT %d: %w", x, err)
+
+	// unwrap an error
+	errors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errors.As(err, &targetErr)
+
+	// test an error
+	errors.Is(err, &targetErr)
+
+	// not declared in the config
+	// TODO(errto): rewrite errs.Code() manually: no equivalent function in the target
+	errs.Code(err)
+}
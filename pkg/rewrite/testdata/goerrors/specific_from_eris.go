@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rotisserie/eris"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	fmt.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	fmt.Errorf("%s: %w", "MESSAGE", err)
+	fmt.Errorf("FORMAT %d: %w", x, err)
+
+	// get the cause of an error
+	errors.Unwrap(err)
+	errors.Unwrap(err)
+
+	// print an error
+	println(err.Error())
+	// TODO(errto): rewrite eris.ToString() manually: the stack trace is not available in the target
+	println(eris.ToString(err, true))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/int128/errto/errkind"
+	jujuerrors "github.com/juju/errors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	fmt.Errorf("FORMAT %d", x)
+
+	// annotate an error
+	fmt.Errorf("%s: %w", "MESSAGE", err)
+	fmt.Errorf("FORMAT %d: %w", x, err)
+
+	// trace an error
+	fmt.Errorf("%w", err)
+
+	// get the cause of an error
+	errors.Unwrap(err)
+	errors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errors.As(err, &targetErr)
+
+	// create an error of the type
+	fmt.Errorf("FORMAT %d %w", x, errkind.ErrNotFound)
+	fmt.Errorf("FORMAT %w", errkind.ErrAlreadyExists)
+
+	// test the type of an error
+	errors.Is(err, errkind.ErrNotFound)
+	errors.Is(err, errkind.ErrAlreadyExists)
+	errors.Is(err, errkind.ErrNotFound)
+
+	// mask an error
+	// TODO(errto): rewrite jujuerrors.Mask() manually: no equivalent function in the target
+	jujuerrors.Mask(err)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func sprintfSyntax(x int, y string, err error) {
+	// create an error with a formatted message
+	errors.New(fmt.Sprintf("FORMAT %d", x))
+
+	// wrap an error with a formatted message
+	fmt.Errorf("%s: %w", fmt.Sprintf("FORMAT %d, %s", x, y), err)
+
+	// wrap an error with a formatted message
+	fmt.Errorf("%s: %s", fmt.Sprintf("FORMAT %d", x), err)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func unsupportedSyntax(format string, args []interface{}, err error) {
+	// create an error
+	errors.New("MESSAGE")
+
+	// wrap an error with a variable format
+	// TODO(errto): rewrite pkgerrors.Wrapf() manually: 2nd argument of Wrapf must be a string literal
+	pkgerrors.Wrapf(err, format)
+	// TODO(errto): rewrite pkgerrors.WithMessagef() manually: 2nd argument of WithMessagef must be a string literal
+	pkgerrors.WithMessagef(err, format)
+
+	// wrap an error with variadic arguments
+	// TODO(errto): rewrite pkgerrors.Wrapf() manually: variadic arguments are not supported
+	pkgerrors.Wrapf(err, "FORMAT %d", args...)
+	// TODO(errto): rewrite pkgerrors.WithMessagef() manually: variadic arguments are not supported
+	pkgerrors.WithMessagef(err, "FORMAT %d", args...)
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"fmt"
+)
+
+func unsupportedSyntax(format string, err error) {
+	// format an error with a variable format
+	fmt.Errorf(format, err)
+}
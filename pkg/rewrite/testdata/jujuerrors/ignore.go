@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/juju/errors"
+)
+
+func ignore(err error) bool {
+	// rewritten
+	_ = errors.Is(err, errors.NotFound)
+
+	// left as they are
+	_ = errors.Is(err, errors.NotFound) //errto:ignore
+	//errto:ignore Is
+	return errors.Is(err, errors.AlreadyExists)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/juju/errors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// annotate an error
+	errors.Annotate(err, "MESSAGE")
+	errors.Annotatef(err, "FORMAT %d", x)
+
+	// trace an error
+	errors.Trace(err)
+
+	// get the cause of an error
+	errors.Cause(err)
+	errors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errors.As(err, &targetErr)
+
+	// create an error of the type
+	errors.NotFoundf("FORMAT %d", x)
+	errors.AlreadyExistsf("FORMAT")
+
+	// test the type of an error
+	errors.IsNotFound(err)
+	errors.IsAlreadyExists(err)
+	errors.Is(err, errors.NotFound)
+
+	// mask an error
+	errors.Mask(err)
+}
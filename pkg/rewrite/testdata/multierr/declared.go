@@ -0,0 +1,13 @@
+package main
+
+import (
+	"go.uber.org/multierr"
+)
+
+func countErrors(err error) int {
+	return len(multierr.Errors(err))
+}
+
+func unwrapErrors(err error) []error {
+	return []error{err}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"go.uber.org/multierr"
+)
+
+func appendAll(errs []error) error {
+	var err error
+	for _, e := range errs {
+		err = multierr.Append(err, e)
+	}
+	return err
+}
+
+func combine(a, b error) error {
+	return multierr.Combine(a, b)
+}
+
+func countErrors(err error) int {
+	return len(multierr.Errors(err))
+}
+
+func appendInto(err *error, e error) bool {
+	return multierr.AppendInto(err, e)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"go.uber.org/multierr"
+)
+
+func combine(a, b error) error {
+	return multierr.Combine(a, b)
+}
+
+func countErrors(err error) int {
+	return len(multierr.Errors(err))
+}
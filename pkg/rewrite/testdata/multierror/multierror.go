@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/hashicorp/go-multierror"
+)
+
+func appendErrors(errs []error) error {
+	var result *multierror.Error
+	for _, e := range errs {
+		// multierror.Append() flattens the errors, but errors.Join() nests them.
+		// The message of the nested errors is the same as the flat ones, and unwrapErrors() flattens them again.
+		result = multierror.Append(result, e)
+	}
+	return result.ErrorOrNil()
+}
+
+func appendAll(a, b error) error {
+	return multierror.Append(a, b).ErrorOrNil()
+}
+
+func countErrors(result *multierror.Error) int {
+	return len(result.Errors)
+}
+
+func wrappedErrors(err error) []error {
+	if merr, ok := err.(*multierror.Error); ok {
+		return merr.WrappedErrors()
+	}
+	return nil
+}
+
+func appendWithFormat(errs []error) error {
+	var result *multierror.Error
+	for _, e := range errs {
+		result = multierror.Append(result, e)
+	}
+	if result != nil {
+		result.ErrorFormat = func(errs []error) string { return "" }
+	}
+	return result.ErrorOrNil()
+}
+
+func setFormat(result *multierror.Error) {
+	result.ErrorFormat = func(errs []error) string { return "" }
+}
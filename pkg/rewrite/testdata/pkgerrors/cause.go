@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func cause(err error) error {
+	// get the root cause of an error
+	return errors.Cause(err)
+}
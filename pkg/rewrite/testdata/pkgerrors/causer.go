@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CauseError is an error with the cause.
+type CauseError struct {
+	err error
+}
+
+func (e *CauseError) Error() string { return "cause error: " + e.err.Error() }
+
+// Cause returns the cause of the error.
+func (e *CauseError) Cause() error { return e.err }
+
+// ValueError is an error with the cause.
+type ValueError struct {
+	err error
+}
+
+func (ValueError) Error() string { return "value error" }
+
+func (ValueError) Cause() error {
+	// comment should be kept
+	return errors.New("cause")
+}
+
+// UnwrapError already has Unwrap.
+type UnwrapError struct {
+	err error
+}
+
+func (e UnwrapError) Error() string { return "unwrap error" }
+func (e UnwrapError) Cause() error  { return e.err }
+func (e UnwrapError) Unwrap() error { return e.err }
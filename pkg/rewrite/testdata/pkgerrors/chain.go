@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+var errNotFound = errors.New("not found")
+
+func cause(err error) error {
+	// walk the chain
+	for err != nil {
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
+
+func causeOnce(err error) error {
+	if c, ok := err.(interface{ Cause() error }); ok {
+		err = c.Cause()
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	for {
+		if err == errNotFound {
+			return true
+		}
+		c, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = c.Unwrap()
+	}
+}
+
+func isNotFoundOrNil(err error) bool {
+	for err != nil {
+		if err == errNotFound {
+			return true
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+func root(err error) error {
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return err
+}
+
+func unwrapValue(v interface{}) interface{} {
+	if u, ok := v.(interface{ Unwrap() error }); ok {
+		v = u.Unwrap()
+	}
+	return v
+}
+
+func rootIgnored(err error) error {
+	//errto:ignore
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return err
+}
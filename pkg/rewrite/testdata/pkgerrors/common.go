@@ -15,7 +15,6 @@ func commonSyntax(x int, y string, err error) {
 	errors.New("MESSAGE")
 
 	// format an error
-	errors.Errorf("FORMAT")
 	errors.Errorf("FORMAT %d", x)
 	errors.Errorf("FORMAT %d, %s", x, y)
 
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	errors.Errorf("MESSAGE")
+	errors.Errorf("100%% MESSAGE")
+
+	// format an error with the message of an error
+	errors.Errorf("FORMAT: %s", err.Error())
+	errors.Errorf("FORMAT %d: %s", x, err.Error())
+}
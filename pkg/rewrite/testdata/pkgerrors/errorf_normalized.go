@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	errors.New("MESSAGE")
+	errors.New("100% MESSAGE")
+
+	// format an error with the message of an error
+	errors.WithMessagef(err, "FORMAT")
+	errors.WithMessagef(err, "FORMAT %d", x)
+}
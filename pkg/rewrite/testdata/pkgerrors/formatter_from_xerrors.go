@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// WrapError is an error with a message and the next error.
+type WrapError struct {
+	msg	string
+	err	error
+}
+
+func (e *WrapError) Error() string {
+	if e.err != nil {
+		return e.msg + ": " + e.err.Error()
+	}
+	return e.msg
+}
+
+// Unwrap returns the next error in the error chain.
+func (e *WrapError) Unwrap() error {
+	return e.err
+}
+
+// CodeError is an error with a code.
+type CodeError struct {
+	code int
+}
+
+func (c CodeError) Error() string {
+	return fmt.Sprintf("error code %d", c.code)
+}
+
+// MessageError is an error which has its own Error().
+type MessageError struct {
+	msg string
+}
+
+func (e *MessageError) Error() string {
+	return "message: " + e.msg
+}
+
+func (e *MessageError) Format(f fmt.State, c rune) {
+	// TODO(errto): rewrite xerrors.FormatError() manually: no equivalent function in the target
+	xerrors.FormatError(e, f, c)
+}
+
+func (e *MessageError) FormatError(p xerrors.Printer) error {
+	p.Print(e.Error())
+	return nil
+}
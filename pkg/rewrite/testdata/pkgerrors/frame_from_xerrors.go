@@ -0,0 +1,33 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// StackError is an error with the frame of the caller.
+type StackError struct {
+	msg	string
+	frame	runtime.Frame
+}
+
+func newStackError(msg string) error {
+	return &StackError{msg: msg, frame: callerFrame(1)}
+}
+
+func (e *StackError) Error() string {
+	return e.msg
+}
+
+func hideChain(err error) error {
+	// hide the chain of an error
+	return errors.Errorf("%v", err)
+}
+
+func callerFrame(skip int) runtime.Frame {
+	pc := make([]uintptr, 1)
+	runtime.Callers(skip+2, pc)
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return frame
+}
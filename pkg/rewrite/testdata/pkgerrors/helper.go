@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+// wrap wraps the error with the message.
+func wrap(err error, msg string) error {
+	return errors.Wrap(err, msg)
+}
+
+func wrapf(err error, format string, args ...interface{}) error {
+	return errors.Wrapf(err, format, args...)
+}
+
+func withStack(err error) error {
+	return errors.WithStack(err)
+}
+
+func withMessage(msg string, err error) error {
+	return errors.WithMessage(err, msg)
+}
+
+// annotate is not inlined, because the call is marked by the ignore directive.
+func annotate(err error, msg string) error {
+	return errors.WithMessage(err, msg) //errto:ignore
+}
+
+// notFound is not a helper, because it has a statement.
+func notFound(name string) error {
+	name = "<" + name + ">"
+	return errors.Errorf("%s not found", name)
+}
+
+func helperSyntax(x int, err error) error {
+	if err := wrap(err, "MESSAGE"); err != nil {
+		return err
+	}
+	if x < 0 {
+		return wrapf(err, "FORMAT %d", x)
+	}
+	if x == 0 {
+		return withMessage("MESSAGE", err)
+	}
+	if x == 1 {
+		return annotate(err, "MESSAGE")
+	}
+	return withStack(err)
+}
+
+func helperReferredAsValue(errs []error) {
+	for _, err := range errs {
+		withStack(err)
+	}
+	f := withStack
+	f(nil)
+}
+
+func helperShadowed(errors []error) error {
+	if len(errors) == 0 {
+		return notFound("errors")
+	}
+	return withMessage("MESSAGE", errors[0])
+}
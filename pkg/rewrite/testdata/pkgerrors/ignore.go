@@ -0,0 +1,24 @@
+//errto:ignore-file Cause // the library returns the error with a stack trace
+
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func ignore(err error) error {
+	// rewritten
+	_ = errors.New("MESSAGE")
+	_ = errors.Wrap(err, "MESSAGE")
+
+	// left as they are
+	//errto:ignore
+	_ = errors.New("MESSAGE")
+	_ = errors.Wrap(err, "MESSAGE") //errto:ignore
+	_ = errors.Wrapf(err,
+		"FORMAT %d", 1) //errto:ignore // a third-party API expects StackTrace()
+	_ = errors.Cause(err)
+
+	//errto:ignore WithStack,github.com/pkg/errors.Wrap
+	return errors.Wrap(errors.WithStack(errors.New("MESSAGE")), "MESSAGE")
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
+)
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+func mixedSyntax(err error) {
+	// rewritten
+	xerrors.New("MESSAGE")
+	xerrors.Errorf("FORMAT: %w", err)
+	xerrors.Errorf("MESSAGE")
+
+	// left as they are
+	errors.New("MESSAGE")
+	errors.Wrap(err, "MESSAGE")
+	fmt.Errorf("MESSAGE")
+}
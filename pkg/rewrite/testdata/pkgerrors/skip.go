@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func skip(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "MESSAGE")
+	}
+	return errors.WithStack(err)
+}
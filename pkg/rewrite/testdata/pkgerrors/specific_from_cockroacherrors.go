@@ -0,0 +1,25 @@
+package main
+
+import (
+	cockroachdberrors "github.com/cockroachdb/errors"
+	"github.com/pkg/errors"
+)
+
+func specificSyntax(x int, err error) {
+	// format an error
+	errors.Errorf("FORMAT %d", x)
+
+	// unwrap an error
+	errors.Unwrap(err)
+
+	// get the root cause of an error
+	errors.Cause(err)
+	errors.Cause(err)
+
+	// hide the chain of an error
+	errors.Errorf("%v", err)
+
+	// add a hint to an error
+	// TODO(errto): rewrite cockroachdberrors.WithHint() manually: no equivalent function in the target
+	cockroachdberrors.WithHint(err, "HINT")
+}
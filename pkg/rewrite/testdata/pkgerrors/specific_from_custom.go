@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/int128/errto/pkg/rewrite/testdata/custom/errs"
+	"github.com/pkg/errors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	errors.Wrap(err, "MESSAGE")
+	errors.Wrapf(err, "FORMAT %d", x)
+
+	// unwrap an error
+	errors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errors.As(err, &targetErr)
+
+	// test an error
+	errors.Is(err, &targetErr)
+
+	// not declared in the config
+	// TODO(errto): rewrite errs.Code() manually: no equivalent function in the target
+	errs.Code(err)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/int128/errto/errkind"
+	jujuerrors "github.com/juju/errors"
+	"github.com/pkg/errors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	errors.New("MESSAGE")
+	errors.Errorf("FORMAT %d", x)
+
+	// annotate an error
+	errors.Wrap(err, "MESSAGE")
+	errors.Wrapf(err, "FORMAT %d", x)
+
+	// trace an error
+	errors.WithStack(err)
+
+	// get the cause of an error
+	errors.Cause(err)
+	errors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	errors.As(err, &targetErr)
+
+	// create an error of the type
+	// TODO(errto): rewrite jujuerrors.NotFoundf() manually: the target cannot append the message of the kind without a colon
+	jujuerrors.NotFoundf("FORMAT %d", x)
+	// TODO(errto): rewrite jujuerrors.AlreadyExistsf() manually: the target cannot append the message of the kind without a colon
+	jujuerrors.AlreadyExistsf("FORMAT")
+
+	// test the type of an error
+	errors.Is(err, errkind.ErrNotFound)
+	errors.Is(err, errkind.ErrAlreadyExists)
+	errors.Is(err, errkind.ErrNotFound)
+
+	// mask an error
+	// TODO(errto): rewrite jujuerrors.Mask() manually: no equivalent function in the target
+	jujuerrors.Mask(err)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func sprintfSyntax(x int, y string, err error) {
+	// create an error with a formatted message
+	errors.New(fmt.Sprintf("FORMAT %d", x))
+
+	// wrap an error with a formatted message
+	errors.Wrap(err, fmt.Sprintf("FORMAT %d, %s", x, y))
+
+	// wrap an error with a formatted message
+	errors.WithMessage(err, fmt.Sprintf("FORMAT %d", x))
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func sprintfSyntax(x int, y string, err error) {
+	// create an error with a formatted message
+	errors.Errorf("FORMAT %d", x)
+
+	// wrap an error with a formatted message
+	errors.Wrapf(err, "FORMAT %d, %s", x, y)
+
+	// wrap an error with a formatted message
+	errors.WithMessagef(err, "FORMAT %d", x)
+}
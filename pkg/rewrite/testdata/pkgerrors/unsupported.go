@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func unsupportedSyntax(format string, args []interface{}, err error) {
+	// create an error
+	errors.New("MESSAGE")
+
+	// wrap an error with a variable format
+	errors.Wrapf(err, format)
+	errors.WithMessagef(err, format)
+
+	// wrap an error with variadic arguments
+	errors.Wrapf(err, "FORMAT %d", args...)
+	errors.WithMessagef(err, "FORMAT %d", args...)
+}
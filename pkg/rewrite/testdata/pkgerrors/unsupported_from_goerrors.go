@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+)
+
+func unsupportedSyntax(format string, err error) {
+	// format an error with a variable format
+	// TODO(errto): rewrite fmt.Errorf() manually: 1st argument of Errorf must be a string literal
+	fmt.Errorf(format, err)
+}
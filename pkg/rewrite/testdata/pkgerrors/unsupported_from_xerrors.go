@@ -0,0 +1,11 @@
+package main
+
+import (
+	"golang.org/x/xerrors"
+)
+
+func unsupportedSyntax(format string, err error) {
+	// format an error with a variable format
+	// TODO(errto): rewrite xerrors.Errorf() manually: 1st argument of Errorf must be a string literal
+	xerrors.Errorf(format, err)
+}
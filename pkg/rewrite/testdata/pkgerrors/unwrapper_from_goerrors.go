@@ -0,0 +1,29 @@
+package main
+
+// UnwrapError is an error with the next error.
+type UnwrapError struct {
+	err error
+}
+
+func (e *UnwrapError) Error() string	{ return "unwrap error: " + e.err.Error() }
+
+// Unwrap returns the next error.
+func (e *UnwrapError) Unwrap() error	{ return e.err }
+
+func (e *UnwrapError) Cause() error	{ return e.Unwrap() }
+
+// NotUnwrapper has a method of the different signature.
+type NotUnwrapper struct{}
+
+func (NotUnwrapper) Error() string	{ return "not unwrapper" }
+func (NotUnwrapper) Unwrap(n int) error	{ return nil }
+
+// CauseFieldError has a field of the same name as Cause().
+type CauseFieldError struct {
+	Cause error
+}
+
+func (e *CauseFieldError) Error() string	{ return "cause field error: " + e.Cause.Error() }
+
+// Unwrap returns the cause.
+func (e *CauseFieldError) Unwrap() error	{ return e.Cause }
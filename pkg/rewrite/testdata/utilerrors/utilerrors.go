@@ -0,0 +1,28 @@
+package main
+
+import (
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func aggregate(errs []error) error {
+	return utilerrors.NewAggregate(errs)
+}
+
+func flatten(agg utilerrors.Aggregate) utilerrors.Aggregate {
+	return utilerrors.Flatten(agg)
+}
+
+func reduce(errs []error) error {
+	return utilerrors.Reduce(utilerrors.NewAggregate(errs))
+}
+
+func countErrors(err error) int {
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		return len(agg.Errors())
+	}
+	return 1
+}
+
+func filterOut(err error) error {
+	return utilerrors.FilterOut(err, func(error) bool { return false })
+}
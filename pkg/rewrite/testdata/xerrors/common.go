@@ -15,7 +15,6 @@ func commonSyntax(x int, y string, err error) {
 	xerrors.New("MESSAGE")
 
 	// format an error
-	xerrors.Errorf("FORMAT")
 	xerrors.Errorf("FORMAT %d", x)
 	xerrors.Errorf("FORMAT %d, %s", x, y)
 
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"golang.org/x/xerrors"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	xerrors.Errorf("MESSAGE")
+	xerrors.Errorf("100%% MESSAGE")
+
+	// format an error with the message of an error
+	xerrors.Errorf("FORMAT: %s", err.Error())
+	xerrors.Errorf("FORMAT %d: %s", x, err.Error())
+}
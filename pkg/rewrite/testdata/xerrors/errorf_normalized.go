@@ -0,0 +1,15 @@
+package main
+
+import (
+	"golang.org/x/xerrors"
+)
+
+func errorfSyntax(x int, err error) {
+	// format an error without any verb
+	xerrors.New("MESSAGE")
+	xerrors.New("100% MESSAGE")
+
+	// format an error with the message of an error
+	xerrors.Errorf("FORMAT: %s", err)
+	xerrors.Errorf("FORMAT %d: %s", x, err)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// WrapError is an error with a message and the next error.
+type WrapError struct {
+	msg string
+	err error
+}
+
+func (e *WrapError) Error() string {
+	return fmt.Sprint(e)
+}
+
+func (e *WrapError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// FormatError prints the message and the detail.
+func (e *WrapError) FormatError(p xerrors.Printer) error {
+	p.Print(e.msg)
+	if p.Detail() {
+		p.Printf("at %s", "somewhere")
+	}
+	return e.err
+}
+
+// CodeError is an error with a code.
+type CodeError struct {
+	code int
+}
+
+func (c CodeError) Error() string {
+	return fmt.Sprintf("%v", c)
+}
+
+func (c CodeError) Format(f fmt.State, r rune) {
+	xerrors.FormatError(c, f, r)
+}
+
+func (c CodeError) FormatError(p xerrors.Printer) error {
+	p.Printf("error code %d", c.code)
+	return nil
+}
+
+// MessageError is an error which has its own Error().
+type MessageError struct {
+	msg string
+}
+
+func (e *MessageError) Error() string {
+	return "message: " + e.msg
+}
+
+func (e *MessageError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+func (e *MessageError) FormatError(p xerrors.Printer) error {
+	p.Print(e.Error())
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// StackError is an error with the frame of the caller.
+type StackError struct {
+	msg   string
+	frame xerrors.Frame
+}
+
+func newStackError(msg string) error {
+	return &StackError{msg: msg, frame: xerrors.Caller(1)}
+}
+
+func (e *StackError) Error() string {
+	return fmt.Sprint(e)
+}
+
+func (e *StackError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+func (e *StackError) FormatError(p xerrors.Printer) error {
+	p.Print(e.msg)
+	e.frame.Format(p)
+	return nil
+}
+
+func hideChain(err error) error {
+	// hide the chain of an error
+	return xerrors.Opaque(err)
+}
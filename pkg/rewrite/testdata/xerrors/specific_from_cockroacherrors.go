@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/cockroachdb/errors"
+	"golang.org/x/xerrors"
+)
+
+func specificSyntax(x int, err error) {
+	// format an error
+	xerrors.Errorf("FORMAT %d", x)
+
+	// unwrap an error
+	xerrors.Unwrap(err)
+
+	// get the root cause of an error
+	xerrors.Unwrap(err)
+	xerrors.Unwrap(err)
+
+	// hide the chain of an error
+	xerrors.Opaque(err)
+
+	// add a hint to an error
+	// TODO(errto): rewrite errors.WithHint() manually: no equivalent function in the target
+	errors.WithHint(err, "HINT")
+}
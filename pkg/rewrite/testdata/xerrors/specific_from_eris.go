@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/rotisserie/eris"
+	"golang.org/x/xerrors"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	xerrors.New("MESSAGE")
+	xerrors.Errorf("FORMAT %d", x)
+
+	// wrap an error
+	xerrors.Errorf("%s: %w", "MESSAGE", err)
+	xerrors.Errorf("FORMAT %d: %w", x, err)
+
+	// get the cause of an error
+	xerrors.Unwrap(err)
+	xerrors.Unwrap(err)
+
+	// print an error
+	println(err.Error())
+	// TODO(errto): rewrite eris.ToString() manually: the stack trace is not available in the target
+	println(eris.ToString(err, true))
+}
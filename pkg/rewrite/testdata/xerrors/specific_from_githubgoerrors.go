@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/go-errors/errors"
+	"golang.org/x/xerrors"
+)
+
+func specificSyntax(x int, err error) {
+	// create an error
+	xerrors.New("MESSAGE")
+	xerrors.Errorf("FORMAT %d", x)
+
+	// wrap an error with the stack trace
+	xerrors.Errorf("%w", err)
+	xerrors.Errorf("%w", err)
+	xerrors.Errorf("%s: %w", "MESSAGE", err)
+
+	// wrap an error with the stack trace of the caller
+	// TODO(errto): rewrite errors.Wrap() manually: skip argument is not supported
+	errors.Wrap(err, 1)
+	// TODO(errto): rewrite errors.WrapPrefix() manually: skip argument is not supported
+	errors.WrapPrefix(err, "MESSAGE", 1)
+
+	// test an error
+	xerrors.Is(err, err)
+
+	// print the stack trace
+	// TODO(errto): rewrite errors.Error manually: the type is not available in the target
+	if e, ok := err.(*errors.Error); ok {
+		// TODO(errto): rewrite e.ErrorStack() manually: the stack trace is not available in the target
+		println(e.ErrorStack())
+	}
+}
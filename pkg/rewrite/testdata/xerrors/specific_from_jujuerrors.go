@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/int128/errto/errkind"
+	"github.com/juju/errors"
+	"golang.org/x/xerrors"
+)
+
+type SomeError struct{}
+
+func (err SomeError) Error() string {
+	return "hello"
+}
+
+func specificSyntax(x int, err error) {
+	// create an error
+	xerrors.New("MESSAGE")
+	xerrors.Errorf("FORMAT %d", x)
+
+	// annotate an error
+	xerrors.Errorf("%s: %w", "MESSAGE", err)
+	xerrors.Errorf("FORMAT %d: %w", x, err)
+
+	// trace an error
+	xerrors.Errorf("%w", err)
+
+	// get the cause of an error
+	xerrors.Unwrap(err)
+	xerrors.Unwrap(err)
+
+	// cast an error
+	var targetErr SomeError
+	xerrors.As(err, &targetErr)
+
+	// create an error of the type
+	// TODO(errto): rewrite errors.NotFoundf() manually: the target cannot append the message of the kind without a colon
+	errors.NotFoundf("FORMAT %d", x)
+	// TODO(errto): rewrite errors.AlreadyExistsf() manually: the target cannot append the message of the kind without a colon
+	errors.AlreadyExistsf("FORMAT")
+
+	// test the type of an error
+	xerrors.Is(err, errkind.ErrNotFound)
+	xerrors.Is(err, errkind.ErrAlreadyExists)
+	xerrors.Is(err, errkind.ErrNotFound)
+
+	// mask an error
+	// TODO(errto): rewrite errors.Mask() manually: no equivalent function in the target
+	errors.Mask(err)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+func sprintfSyntax(x int, y string, err error) {
+	// create an error with a formatted message
+	xerrors.New(fmt.Sprintf("FORMAT %d", x))
+
+	// wrap an error with a formatted message
+	xerrors.Errorf("%s: %w", fmt.Sprintf("FORMAT %d, %s", x, y), err)
+
+	// wrap an error with a formatted message
+	xerrors.Errorf("%s: %s", fmt.Sprintf("FORMAT %d", x), err)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"golang.org/x/xerrors"
+)
+
+func sprintfSyntax(x int, y string, err error) {
+	// create an error with a formatted message
+	xerrors.Errorf("FORMAT %d", x)
+
+	// wrap an error with a formatted message
+	xerrors.Errorf("FORMAT %d, %s: %w", x, y, err)
+
+	// wrap an error with a formatted message
+	xerrors.Errorf("FORMAT %d: %s", x, err)
+}
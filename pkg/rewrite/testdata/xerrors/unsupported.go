@@ -0,0 +1,10 @@
+package main
+
+import (
+	"golang.org/x/xerrors"
+)
+
+func unsupportedSyntax(format string, err error) {
+	// format an error with a variable format
+	xerrors.Errorf(format, err)
+}
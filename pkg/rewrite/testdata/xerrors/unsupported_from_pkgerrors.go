@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
+)
+
+func unsupportedSyntax(format string, args []interface{}, err error) {
+	// create an error
+	xerrors.New("MESSAGE")
+
+	// wrap an error with a variable format
+	// TODO(errto): rewrite errors.Wrapf() manually: 2nd argument of Wrapf must be a string literal
+	errors.Wrapf(err, format)
+	// TODO(errto): rewrite errors.WithMessagef() manually: 2nd argument of WithMessagef must be a string literal
+	errors.WithMessagef(err, format)
+
+	// wrap an error with variadic arguments
+	// TODO(errto): rewrite errors.Wrapf() manually: variadic arguments are not supported
+	errors.Wrapf(err, "FORMAT %d", args...)
+	// TODO(errto): rewrite errors.WithMessagef() manually: variadic arguments are not supported
+	errors.WithMessagef(err, "FORMAT %d", args...)
+}
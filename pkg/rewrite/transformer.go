@@ -4,22 +4,37 @@ import (
 	"go/ast"
 
 	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/config"
 	"github.com/int128/errto/pkg/log"
 	"golang.org/x/tools/go/packages"
 )
 
 type Transformer interface {
-	Transform(pkg *packages.Package, file *ast.File) (int, error)
+	Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error)
 }
 
-func newTransformer(m Method) Transformer {
+// transformerOption represents the options of the transformers, which are shared by all files.
+type transformerOption struct {
+	sentinels  *sentinelPackage
+	joinErrors bool // errors.Join is available in Go 1.20 or later
+
+	customs      customPackages
+	customTarget *config.Package // required if the method is Custom
+	filter       *callFilter
+}
+
+func newTransformer(m Method, opt transformerOption) Transformer {
 	switch m {
 	case Xerrors:
-		return &toXerrors{}
+		return &toXerrors{sentinels: opt.sentinels, customs: opt.customs, filter: opt.filter}
 	case GoErrors:
-		return &toGoErrors{}
+		return &toGoErrors{sentinels: opt.sentinels, customs: opt.customs, filter: opt.filter, joinErrors: opt.joinErrors}
 	case PkgErrors:
-		return &toPkgErrors{}
+		return &toPkgErrors{sentinels: opt.sentinels, customs: opt.customs, filter: opt.filter}
+	case CockroachErrors:
+		return &toCockroachErrors{sentinels: opt.sentinels, customs: opt.customs, filter: opt.filter}
+	case Custom:
+		return &toCustom{target: opt.customTarget, customs: opt.customs, filter: opt.filter, sentinels: opt.sentinels}
 	}
 	return nil
 }
@@ -32,3 +47,50 @@ func replacePackageFunctionCall(call astio.PackageFunctionCall, newPkgName, newF
 	call.TargetPkg.Name = newPkgName
 	call.TargetFun.Sel.Name = newFunName
 }
+
+// replaceRenamedFunctionCall renames the function call by rename and then rewrites it by replace.
+// If either of them returns an error, the function name and arguments are restored.
+func replaceRenamedFunctionCall(call astio.PackageFunctionCall, rename, replace func(call astio.PackageFunctionCall) error) error {
+	name, args := call.FunctionName(), call.Args()
+	err := rename(call)
+	if err == nil {
+		err = replace(call)
+	}
+	if err != nil {
+		call.TargetFun.Sel.Name = name
+		call.SetArgs(args)
+		return err
+	}
+	return nil
+}
+
+// replaceNewSprintfWithErrorf rewrites New(fmt.Sprintf("FORMAT", ...)) to Errorf("FORMAT", ...).
+// It returns false if the argument is not a call of fmt.Sprintf.
+func replaceNewSprintfWithErrorf(call astio.PackageFunctionCall, newPkgName string) bool {
+	args := call.Args()
+	if len(args) != 1 {
+		return false
+	}
+	newArgs, ok := sprintfArgs(call.TypesInfo, args[0])
+	if !ok {
+		return false
+	}
+	call.SetArgs(newArgs)
+	replacePackageFunctionCall(call, newPkgName, "Errorf")
+	return true
+}
+
+// usesPackageName returns true if the file has a selector of the package name,
+// regardless of whether the package is imported or not.
+func usesPackageName(file *ast.File, name string) bool {
+	var used bool
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == name && x.Obj == nil {
+				used = true
+			}
+		}
+		return !used
+	})
+	return used
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/int128/errto/pkg/astio"
 )
 
-func transform(t *testing.T, transformer Transformer, fixtureFilename, wantFilename string) {
+func transform(t *testing.T, transformer Transformer, fixtureFilename, wantFilename string) []Diagnostic {
 	tempDir, err := ioutil.TempDir(".", "fixture")
 	if err != nil {
 		t.Fatalf("could not create a temp dir: %s", err)
@@ -49,11 +49,11 @@ func transform(t *testing.T, transformer Transformer, fixtureFilename, wantFilen
 	if len(pkgs[0].Syntax) != 1 {
 		t.Fatalf("len(pkgs[0].Syntax) wants 1 but was %d", len(pkgs[0].Syntax))
 	}
-	n, err := transformer.Transform(pkgs[0], pkgs[0].Syntax[0])
+	n, diagnostics, err := transformer.Transform(pkgs[0], pkgs[0].Syntax[0])
 	if err != nil {
 		t.Fatalf("could not transform: %s", err)
 	}
-	t.Logf("%d change(s)", n)
+	t.Logf("%d change(s), %d diagnostic(s)", n, len(diagnostics))
 	var w strings.Builder
 	if err := printer.Fprint(&w, pkgs[0].Fset, pkgs[0].Syntax[0]); err != nil {
 		t.Fatalf("could not print the AST: %s", err)
@@ -68,6 +68,7 @@ func transform(t *testing.T, transformer Transformer, fixtureFilename, wantFilen
 	if diff := diffLines(want, got); diff != "" {
 		t.Errorf("mismatch (-want +got):\n%s", diff)
 	}
+	return diagnostics
 }
 
 func diffLines(a string, b string) string {
@@ -0,0 +1,169 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/int128/errto/pkg/astio"
+	"github.com/int128/errto/pkg/log"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// reduceErrorsFuncName is the name of the function which replaces utilerrors.Reduce().
+const reduceErrorsFuncName = "reduceErrors"
+
+// reduceErrorsFuncDecl reduces the joined errors by one level, like utilerrors.Reduce().
+const reduceErrorsFuncDecl = `func reduceErrors(err error) error {
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+	switch errs := u.Unwrap(); len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return err
+}
+`
+
+// migrateAggregates rewrites the syntax of k8s.io/apimachinery/pkg/util/errors,
+// which is not a package function call, to the syntax of errors.Join().
+//
+// It rewrites the following syntax:
+//
+//	utilerrors.Flatten(agg)  -> utilerrors.NewAggregate(unwrapErrors(agg))
+//	utilerrors.Reduce(err)   -> reduceErrors(err)
+//	utilerrors.Aggregate     -> error (in a declaration of variable, field, parameter or result)
+//	agg.Errors()             -> unwrapErrors(agg)
+//
+// utilerrors.NewAggregate() is rewritten to errors.Join() by the visitor.
+// The functions unwrapErrors() and reduceErrors() are added to the package if needed.
+//
+// The other methods and types, such as a type assertion to utilerrors.Aggregate, are reported.
+// The calls marked by the ignore directive or not selected by the filter are left as they are.
+// It returns the number of changes.
+func migrateAggregates(pkg *packages.Package, file *ast.File, ignored map[*ast.CallExpr]bool, filter *callFilter, r *reporter) int {
+	canUnwrap := pkg.Types.Scope().Lookup(unwrapErrorsFuncName) == nil
+	canReduce := pkg.Types.Scope().Lookup(reduceErrorsFuncName) == nil
+	var n int
+	var needUnwrapErrors, needReduceErrors bool
+	// replace the types before the children are visited
+	pre := func(c *astutil.Cursor) bool {
+		sel, ok := c.Node().(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName)
+		if !ok || pkgName.Imported().Path() != utilerrorsImportPath {
+			return true
+		}
+		obj, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.TypeName)
+		if !ok {
+			return true
+		}
+		if isAggregate(obj.Type()) {
+			switch parent := c.Parent().(type) {
+			case *ast.ValueSpec:
+				if parent.Type == sel {
+					log.Printf("%s: %s.Aggregate -> error", astio.Position(pkg, sel), x.Name)
+					c.Replace(&ast.Ident{NamePos: sel.Pos(), Name: "error"})
+					n++
+					return false
+				}
+			case *ast.Field:
+				log.Printf("%s: %s.Aggregate -> error", astio.Position(pkg, sel), x.Name)
+				c.Replace(&ast.Ident{NamePos: sel.Pos(), Name: "error"})
+				n++
+				return false
+			}
+		}
+		r.reportRef(astio.Position(pkg, sel), sel, utilerrorsImportPath, errors.New("the type is not available in errors.Join"))
+		return false
+	}
+	// replace the expressions after the children are visited, so that the children are kept
+	post := func(c *astutil.Cursor) bool {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || ignored[call] {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := fun.X.(*ast.Ident); ok && len(call.Args) == 1 {
+			if pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName); ok && pkgName.Imported().Path() == utilerrorsImportPath {
+				if !filter.selectedFunction(utilerrorsImportPath, fun.Sel.Name) {
+					return true
+				}
+				switch {
+				case fun.Sel.Name == "Flatten" && canUnwrap:
+					log.Printf("%s: %s.Flatten() -> %s.NewAggregate(%s())", astio.Position(pkg, call), x.Name, x.Name, unwrapErrorsFuncName)
+					fun.Sel.Name = "NewAggregate"
+					call.Args[0] = newFuncCall(unwrapErrorsFuncName, call.Args[0])
+					needUnwrapErrors = true
+					n++
+				case fun.Sel.Name == "Reduce" && canReduce:
+					log.Printf("%s: %s.Reduce() -> %s()", astio.Position(pkg, call), x.Name, reduceErrorsFuncName)
+					c.Replace(newFuncCall(reduceErrorsFuncName, call.Args[0]))
+					needReduceErrors = true
+					n++
+				}
+				return true
+			}
+		}
+		s, ok := pkg.TypesInfo.Selections[fun]
+		if !ok || s.Kind() != types.MethodVal || !isAggregate(s.Recv()) {
+			return true
+		}
+		if !filter.selectedFunction(utilerrorsImportPath, "Aggregate."+fun.Sel.Name) {
+			return true
+		}
+		switch fun.Sel.Name {
+		case "Error":
+			return true
+		case "Errors":
+			if canUnwrap {
+				log.Printf("%s: Errors() -> %s()", astio.Position(pkg, call), unwrapErrorsFuncName)
+				c.Replace(newFuncCall(unwrapErrorsFuncName, fun.X))
+				needUnwrapErrors = true
+				n++
+				return true
+			}
+		}
+		reason := "the method is not available in errors.Join"
+		r.add(Diagnostic{
+			Position: astio.Position(pkg, call),
+			Function: utilerrorsImportPath + ".Aggregate." + fun.Sel.Name,
+			Reason:   reason,
+		})
+		addTODOComment(file, call, fmt.Sprintf("rewrite %s manually: %s", exprString(pkg.Fset, call), reason))
+		n++
+		return true
+	}
+	astutil.Apply(file, pre, post)
+	if needUnwrapErrors {
+		n += addFuncDecl(pkg, file, unwrapErrorsFuncName, unwrapErrorsFuncDecl)
+	}
+	if needReduceErrors {
+		n += addFuncDecl(pkg, file, reduceErrorsFuncName, reduceErrorsFuncDecl)
+	}
+	return n
+}
+
+// isAggregate returns true if the type is k8s.io/apimachinery/pkg/util/errors.Aggregate.
+func isAggregate(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == utilerrorsImportPath && named.Obj().Name() == "Aggregate"
+}
@@ -0,0 +1,43 @@
+package rewrite
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/int128/errto/pkg/astio"
+	"golang.org/x/tools/go/packages"
+)
+
+// selectVariants returns the syntax trees to rewrite, so that each physical file is processed once.
+//
+// A file is loaded into both the package and its test variant, e.g. p and p [p.test].
+// It selects the variant which has the most files, because it has the most complete type information,
+// e.g. the references from the test files.
+func selectVariants(pkgs []*packages.Package) map[*ast.File]bool {
+	type variant struct {
+		pkg  *packages.Package
+		file *ast.File
+	}
+	variants := make(map[string]variant)
+	var order []string
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue // generated main package of tests
+		}
+		for _, file := range pkg.Syntax {
+			name := astio.SourceFilename(pkg, file)
+			v, ok := variants[name]
+			if !ok {
+				order = append(order, name)
+			}
+			if !ok || len(pkg.Syntax) > len(v.pkg.Syntax) {
+				variants[name] = variant{pkg: pkg, file: file}
+			}
+		}
+	}
+	selected := make(map[*ast.File]bool)
+	for _, name := range order {
+		selected[variants[name].file] = true
+	}
+	return selected
+}
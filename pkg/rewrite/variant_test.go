@@ -0,0 +1,53 @@
+package rewrite
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestSelectVariants(t *testing.T) {
+	fset := token.NewFileSet()
+	parse := func(name, src string) *ast.File {
+		file, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("ParseFile error: %s", err)
+		}
+		return file
+	}
+	p := &packages.Package{ID: "p", Fset: fset, Syntax: []*ast.File{
+		parse("/src/p/a.go", "package p"),
+	}}
+	pt := &packages.Package{ID: "p [p.test]", Fset: fset, Syntax: []*ast.File{
+		parse("/src/p/a.go", "package p"),
+		parse("/src/p/a_test.go", "package p"),
+	}}
+	pxt := &packages.Package{ID: "p_test [p.test]", Fset: fset, Syntax: []*ast.File{
+		parse("/src/p/x_test.go", "package p_test"),
+	}}
+	main := &packages.Package{ID: "p.test", Fset: fset, Syntax: []*ast.File{
+		parse("/cache/p.test/testmain.go", "package main"),
+	}}
+	selected := selectVariants([]*packages.Package{p, pt, pxt, main})
+	if len(selected) != 3 {
+		t.Errorf("len(selected) wants 3 but was %d", len(selected))
+	}
+	for _, c := range []struct {
+		pkg  *packages.Package
+		want []bool
+	}{
+		{p, []bool{false}},
+		{pt, []bool{true, true}},
+		{pxt, []bool{true}},
+		{main, []bool{false}},
+	} {
+		for i, file := range c.pkg.Syntax {
+			if got := selected[file]; got != c.want[i] {
+				t.Errorf("selected[%s: %s] wants %v but was %v", c.pkg.ID, fset.Position(file.Pos()).Filename, c.want[i], got)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package rewrite
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
@@ -12,31 +13,72 @@ import (
 	"golang.org/x/tools/go/packages"
 )
 
-type toXerrors struct{}
+type toXerrors struct {
+	sentinels *sentinelPackage
+	customs   customPackages
+	filter    *callFilter
+}
 
-func (t *toXerrors) Transform(pkg *packages.Package, file *ast.File) (int, error) {
+func (t *toXerrors) Transform(pkg *packages.Package, file *ast.File) (int, []Diagnostic, error) {
 	var v toXerrorsVisitor
-	if err := astio.Inspect(pkg, file, &v); err != nil {
-		return 0, fmt.Errorf("could not inspect the file: %w", err)
+	v.sentinels = t.sentinels
+	v.customs = t.customs
+	v.filter = t.filter
+	// compute the ignored calls once, before the passes replace any node of the file
+	ignored := astio.IgnoredCalls(pkg, file)
+	m := addDelegateMethods(pkg, file, "Cause", "Unwrap", &v.reporter)
+	if t.filter.selected(jujuErrorsImportPath) {
+		m += v.replaceJujuConstErrors(pkg, file, ignored, t.filter, &v.reporter)
+	}
+	if t.filter.selected(erisImportPath) {
+		m += replaceErisToString(pkg, file, ignored, t.filter)
 	}
-	if v.needImport == 0 {
-		return 0, nil
+	reportSourceTypes(pkg, file, t.filter, &v.reporter)
+	t.filter.keepUnselectedImports(file, t.customs, xerrorsImportPath, &v.reporter)
+	if err := astio.InspectWithIgnoredCalls(pkg, file, ignored, &v); err != nil {
+		return 0, nil, fmt.Errorf("could not inspect the file: %w", err)
 	}
-	n := t.replaceImports(pkg, file)
-	return v.needImport + n, nil
+	r := normalizeErrorf(pkg, file, ignored, t.filter, xerrorsImportPath, "xerrors", "xerrors")
+	c := rewriteChainWalks(pkg, file, t.filter, Xerrors, "xerrors")
+	if v.needImport == 0 && r.changes == 0 && m == 0 && c == 0 && len(v.remainingCalls) == 0 && len(v.remainingRefs) == 0 && len(v.remainingMethods) == 0 {
+		return 0, v.diagnostics, nil
+	}
+	n := v.addSentinelImport(pkg, file)
+	n += t.replaceImports(pkg, file, v.needImport+c, v.remainingImportPaths())
+	n += keepRemainingCalls(pkg, file, &v.reporter)
+	return v.needImport + r.changes + m + c + n, v.diagnostics, nil
 }
 
-func (*toXerrors) replaceImports(pkg *packages.Package, file *ast.File) int {
+func (t *toXerrors) replaceImports(pkg *packages.Package, file *ast.File, needImport int, keepImports map[string]bool) int {
 	var n int
-	if astutil.AddImport(pkg.Fset, file, xerrorsImportPath) {
-		n++
-		log.Printf("%s: + import %s", astio.Filename(pkg, file), xerrorsImportPath)
+	if needImport > 0 {
+		if astutil.AddImport(pkg.Fset, file, xerrorsImportPath) {
+			n++
+			log.Printf("%s: + import %s", astio.Filename(pkg, file), xerrorsImportPath)
+		}
 	}
-	if astutil.DeleteImport(pkg.Fset, file, pkgErrorsImportPath) {
+	if !keepImports[pkgErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, pkgErrorsImportPath) {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), pkgErrorsImportPath)
 	}
-	if astutil.DeleteImport(pkg.Fset, file, "errors") {
+	if !keepImports[cockroachErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, cockroachErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), cockroachErrorsImportPath)
+	}
+	if !keepImports[jujuErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, jujuErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), jujuErrorsImportPath)
+	}
+	if !keepImports[githubGoErrorsImportPath] && astutil.DeleteImport(pkg.Fset, file, githubGoErrorsImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), githubGoErrorsImportPath)
+	}
+	if !keepImports[erisImportPath] && astutil.DeleteImport(pkg.Fset, file, erisImportPath) {
+		n++
+		log.Printf("%s: - import %s", astio.Filename(pkg, file), erisImportPath)
+	}
+	n += t.customs.deleteImports(pkg, file, keepImports)
+	if !keepImports["errors"] && astutil.DeleteImport(pkg.Fset, file, "errors") {
 		n++
 		log.Printf("%s: - import %s", astio.Filename(pkg, file), "errors")
 	}
@@ -53,38 +95,66 @@ func (*toXerrors) replaceImports(pkg *packages.Package, file *ast.File) int {
 }
 
 type toXerrorsVisitor struct {
+	reporter
+	jujuErrors
+	customs    customPackages
+	filter     *callFilter
 	needImport int
 }
 
 func (v *toXerrorsVisitor) PackageFunctionCall(call astio.PackageFunctionCall) error {
+	if skipCall(v.filter, call, &v.reporter) {
+		return nil
+	}
+	var err error
 	switch call.PackagePath() {
 	case pkgErrorsImportPath:
-		return v.pkgErrorsFunctionCall(call)
+		err = v.pkgErrorsFunctionCall(call)
 	case "errors":
-		return v.goErrorsFunctionCall(call)
+		err = v.goErrorsFunctionCall(call)
 	case "fmt":
-		return v.goFmtFunctionCall(call)
+		err = v.goFmtFunctionCall(call)
+	case cockroachErrorsImportPath:
+		err = v.cockroachErrorsFunctionCall(call)
+	case jujuErrorsImportPath:
+		err = v.jujuErrorsFunctionCall(call, v.pkgErrorsFunctionCall)
+	case githubGoErrorsImportPath:
+		err = replaceRenamedFunctionCall(call, renameGitHubGoErrorsFunction, v.pkgErrorsFunctionCall)
+	case erisImportPath:
+		err = replaceRenamedFunctionCall(call, renameErisFunction, v.pkgErrorsFunctionCall)
+	default:
+		if v.customs.find(call.PackagePath()) != nil {
+			err = replaceRenamedFunctionCall(call, v.customs.renameFunction, v.pkgErrorsFunctionCall)
+		}
+	}
+	if err != nil {
+		v.report(call, err)
 	}
 	return nil
 }
 
 func (v *toXerrorsVisitor) goErrorsFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
-	case "New", "Unwrap", "As", "Is":
+	case "New":
+		if !replaceNewSprintfWithErrorf(call, "xerrors") {
+			replacePackageFunctionCall(call, "xerrors", "")
+		}
+		v.needImport++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "xerrors", "")
 		v.needImport++
 		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "xerrors"
-	v.needImport++
-	return nil
+	return errUnsupportedFunction
 }
 
 func (v *toXerrorsVisitor) goFmtFunctionCall(call astio.PackageFunctionCall) error {
 	switch call.FunctionName() {
 	case "Errorf":
+		call.SetArgs(inlineSprintf(call.TypesInfo, call.Args()))
 		replacePackageFunctionCall(call, "xerrors", "")
 		v.needImport++
 		return nil
@@ -96,13 +166,16 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 	switch call.FunctionName() {
 	case "Wrapf":
 		args := call.Args()
+		if len(args) < 2 {
+			return fmt.Errorf("errors.Wrapf expects 2 or more arguments but has %d arguments", len(args))
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
 		// append %w to the format arg
 		b, ok := args[1].(*ast.BasicLit)
-		if !ok {
-			return fmt.Errorf("%s: 2nd argument of Wrapf must be a literal but was %T", call.Position, args[1])
-		}
-		if b.Kind != token.STRING {
-			return fmt.Errorf("%s: 2nd argument of Wrapf must be a string but was %s", call.Position, b.Kind)
+		if !ok || b.Kind != token.STRING {
+			return errors.New("2nd argument of Wrapf must be a string literal")
 		}
 		b.Value = strings.TrimSuffix(b.Value, `"`) + `: %w"`
 
@@ -111,13 +184,26 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 		newArgs = append(newArgs, args[1])
 		newArgs = append(newArgs, args[2:]...)
 		newArgs = append(newArgs, args[0])
-		call.SetArgs(newArgs)
+		call.SetArgs(inlineSprintf(call.TypesInfo, newArgs))
 
 		replacePackageFunctionCall(call, "xerrors", "Errorf")
 		v.needImport++
 		return nil
 
-	case "Errorf", "New", "Unwrap", "As", "Is":
+	case "Errorf":
+		call.SetArgs(inlineSprintf(call.TypesInfo, call.Args()))
+		replacePackageFunctionCall(call, "xerrors", "")
+		v.needImport++
+		return nil
+
+	case "New":
+		if !replaceNewSprintfWithErrorf(call, "xerrors") {
+			replacePackageFunctionCall(call, "xerrors", "")
+		}
+		v.needImport++
+		return nil
+
+	case "Unwrap", "As", "Is":
 		replacePackageFunctionCall(call, "xerrors", "")
 		v.needImport++
 		return nil
@@ -130,13 +216,13 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 	case "Wrap":
 		args := call.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("%s: errors.Wrap expects 2 arguments but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.Wrap expects 2 arguments but has %d arguments", len(args))
 		}
-		call.SetArgs([]ast.Expr{
-			&ast.BasicLit{Value: `"%s: %w"`},
+		call.SetArgs(inlineSprintf(call.TypesInfo, []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%s: %w"`},
 			args[1],
 			args[0],
-		})
+		}))
 		replacePackageFunctionCall(call, "xerrors", "Errorf")
 		v.needImport++
 		return nil
@@ -144,7 +230,7 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 	case "WithStack":
 		args := call.Args()
 		if len(args) != 1 {
-			return fmt.Errorf("%s: errors.WithStack expects 1 argument but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.WithStack expects 1 argument but has %d arguments", len(args))
 		}
 		call.SetArgs([]ast.Expr{
 			&ast.BasicLit{Value: `"%w"`},
@@ -157,26 +243,29 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 	case "WithMessage":
 		args := call.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("%s: errors.WithMessage expects 2 arguments but has %d arguments", call.Position, len(args))
+			return fmt.Errorf("errors.WithMessage expects 2 arguments but has %d arguments", len(args))
 		}
-		call.SetArgs([]ast.Expr{
-			&ast.BasicLit{Value: `"%s: %s"`},
+		call.SetArgs(inlineSprintf(call.TypesInfo, []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: `"%s: %s"`},
 			args[1],
 			args[0],
-		})
+		}))
 		replacePackageFunctionCall(call, "xerrors", "Errorf")
 		v.needImport++
 		return nil
 
 	case "WithMessagef":
 		args := call.Args()
+		if len(args) < 2 {
+			return fmt.Errorf("errors.WithMessagef expects 2 or more arguments but has %d arguments", len(args))
+		}
+		if call.Call.Ellipsis.IsValid() {
+			return errors.New("variadic arguments are not supported")
+		}
 		// append %s to the format arg
 		b, ok := args[1].(*ast.BasicLit)
-		if !ok {
-			return fmt.Errorf("%s: 2nd argument of WithMessagef must be a literal but %T", call.Position, args[1])
-		}
-		if b.Kind != token.STRING {
-			return fmt.Errorf("%s: 2nd argument of WithMessagef must be a string but %s", call.Position, b.Kind)
+		if !ok || b.Kind != token.STRING {
+			return errors.New("2nd argument of WithMessagef must be a string literal")
 		}
 		b.Value = strings.TrimSuffix(b.Value, `"`) + `: %s"`
 
@@ -185,15 +274,22 @@ func (v *toXerrorsVisitor) pkgErrorsFunctionCall(call astio.PackageFunctionCall)
 		newArgs = append(newArgs, args[1])
 		newArgs = append(newArgs, args[2:]...)
 		newArgs = append(newArgs, args[0])
-		call.SetArgs(newArgs)
+		call.SetArgs(inlineSprintf(call.TypesInfo, newArgs))
 
 		replacePackageFunctionCall(call, "xerrors", "Errorf")
 		v.needImport++
 		return nil
 	}
 
-	log.Printf("%s: NOTE: you need to manually rewrite %s.%s()", call.Position, call.TargetPkg.Name, call.FunctionName())
-	call.TargetPkg.Name = "xerrors"
-	v.needImport++
-	return nil
+	return errUnsupportedFunction
+}
+
+func (v *toXerrorsVisitor) cockroachErrorsFunctionCall(call astio.PackageFunctionCall) error {
+	renameCockroachErrorsFunction(call)
+	if call.FunctionName() == "Opaque" {
+		replacePackageFunctionCall(call, "xerrors", "")
+		v.needImport++
+		return nil
+	}
+	return v.pkgErrorsFunctionCall(call)
 }
@@ -20,4 +20,80 @@ func TestToXerrors_Transform(t *testing.T) {
 			"testdata/pkgerrors/common.go",
 			"testdata/xerrors/common.go")
 	})
+	t.Run("fmt.Sprintf from go-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/sprintf.go",
+			"testdata/xerrors/sprintf_collapsed.go")
+	})
+	t.Run("fmt.Sprintf from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/sprintf.go",
+			"testdata/xerrors/sprintf_collapsed.go")
+	})
+	t.Run("Errorf from go-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/goerrors/errorf.go",
+			"testdata/xerrors/errorf_normalized.go")
+	})
+	t.Run("Errorf from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/errorf.go",
+			"testdata/xerrors/errorf_normalized.go")
+	})
+	t.Run("unsupported syntax from pkg-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/pkgerrors/unsupported.go",
+			"testdata/xerrors/unsupported_from_pkgerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("causer from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/causer.go",
+			"testdata/xerrors/causer_from_pkgerrors.go")
+	})
+	t.Run("chain walk from pkg-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/pkgerrors/chain.go",
+			"testdata/xerrors/chain_from_pkgerrors.go")
+	})
+	t.Run("common syntax from cockroach-errors", func(t *testing.T) {
+		transform(t, &tr,
+			"testdata/cockroacherrors/common.go",
+			"testdata/xerrors/common.go")
+	})
+	t.Run("specific syntax from cockroach-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/cockroacherrors/specific.go",
+			"testdata/xerrors/specific_from_cockroacherrors.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from juju-errors", func(t *testing.T) {
+		tr := toXerrors{sentinels: &sentinelPackage{Path: "github.com/int128/errto/errkind"}}
+		diagnostics := transform(t, &tr,
+			"testdata/jujuerrors/specific.go",
+			"testdata/xerrors/specific_from_jujuerrors.go")
+		if len(diagnostics) != 3 {
+			t.Errorf("len(diagnostics) wants 3 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from github go-errors", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/githubgoerrors/specific.go",
+			"testdata/xerrors/specific_from_githubgoerrors.go")
+		if len(diagnostics) != 4 {
+			t.Errorf("len(diagnostics) wants 4 but was %d", len(diagnostics))
+		}
+	})
+	t.Run("specific syntax from eris", func(t *testing.T) {
+		diagnostics := transform(t, &tr,
+			"testdata/eris/specific.go",
+			"testdata/xerrors/specific_from_eris.go")
+		if len(diagnostics) != 1 {
+			t.Errorf("len(diagnostics) wants 1 but was %d", len(diagnostics))
+		}
+	})
 }